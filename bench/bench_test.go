@@ -0,0 +1,112 @@
+package bench_test
+
+import (
+	"arithmos/bench"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const output = `goos: linux
+goarch: amd64
+pkg: arithmos/lib
+BenchmarkConstruction/heapTree/jeu_1_nb_cles_1000.txt-8         	   17772	     60000 ns/op
+BenchmarkConstruction/heapTree/jeu_2_nb_cles_1000.txt-8         	   17772	     80000 ns/op
+BenchmarkConstruction/heapTree/extra_jeu_nb_cles_500000.txt-8   	      20	  50000000 ns/op	  128 B/op
+BenchmarkConstructionWords/heapArray/shakespeare_words_4000-8   	    1000	   1000000 ns/op
+BenchmarkSupprMinWords/heapArray/shakespeare_words_4000-8       	     500	   3000000 ns/op
+BenchmarkMd5-8                                                  	 1000000	      1000 ns/op
+PASS
+ok  	arithmos/lib	12.345s
+`
+
+func TestParse(t *testing.T) {
+	results, err := bench.Parse(strings.NewReader(output))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(results))
+
+	first := results[0]
+	assert.Equal(t, "Construction", first.Benchmark)
+	assert.Equal(t, "heapTree", first.Impl)
+	assert.Equal(t, "jeu_1_nb_cles_1000.txt", first.Dataset)
+	assert.Equal(t, 1000, first.Size)
+	assert.Equal(t, 1, first.Jeu)
+	assert.Equal(t, 17772, first.Iterations)
+	assert.Equal(t, 60000.0, first.NsPerOp)
+
+	extra := results[2]
+	assert.Equal(t, 500000, extra.Size)
+	assert.Equal(t, 0, extra.Jeu)
+	assert.Equal(t, 128.0, extra.Metrics["B/op"])
+
+	assert.Equal(t, 0, results[3].Size)
+	assert.Equal(t, "Md5", results[5].Benchmark)
+	assert.Equal(t, "", results[5].Impl)
+
+	_, err = bench.Parse(strings.NewReader("BenchmarkMd5-8 many 1000 ns/op"))
+	assert.NotNil(t, err)
+}
+
+func TestAggregate(t *testing.T) {
+	results, _ := bench.Parse(strings.NewReader(output))
+	points := bench.Aggregate(results)
+	assert.Equal(t, []bench.Point{
+		{Benchmark: "Construction", Impl: "heapTree", Size: 1000, TimeMs: 0.07, Runs: 2},
+		{Benchmark: "Construction", Impl: "heapTree", Size: 500000, TimeMs: 50, Runs: 1},
+	}, points)
+
+	var csv bytes.Buffer
+	assert.Nil(t, bench.WriteCSV(&csv, points))
+	assert.Equal(t, "benchmark,impl,size,time_ms,runs\n"+
+		"Construction,heapTree,1000,0.07,2\n"+
+		"Construction,heapTree,500000,50,1\n", csv.String())
+
+	var json bytes.Buffer
+	assert.Nil(t, bench.WriteJSON(&json, points))
+	assert.Contains(t, json.String(), `"time_ms": 0.07`)
+}
+
+func TestPlots(t *testing.T) {
+	points := []bench.Point{
+		{Benchmark: "ConstructionWords", Impl: "heapArray", Size: 4000, TimeMs: 1},
+		{Benchmark: "SupprMinWords", Impl: "heapArray", Size: 4000, TimeMs: 3},
+		{Benchmark: "Construction", Impl: "heapTree", Size: 1000, TimeMs: 0.07},
+		{Benchmark: "Construction", Impl: "heapTree", Size: 500000, TimeMs: 50},
+	}
+
+	plot := bench.Plot{Name: "supprmin", Bars: true, Curves: []bench.Curve{
+		{"min heap array", "SupprMinWords", "heapArray", "ConstructionWords"},
+		{"min heap tree", "SupprMinWords", "heapTree", "ConstructionWords"},
+	}}
+	series := plot.Series(points)
+	assert.Equal(t, 1, len(series))
+	assert.Equal(t, "min heap array", series[0].Label)
+	assert.Equal(t, 2.0, series[0].Points[0].TimeMs)
+
+	var svg bytes.Buffer
+	drawn, err := plot.WriteSVG(&svg, points)
+	assert.Nil(t, err)
+	assert.True(t, drawn)
+	assert.True(t, strings.HasPrefix(svg.String(), "<svg"))
+	assert.Contains(t, svg.String(), "<rect x=")
+	assert.Contains(t, svg.String(), "min heap array")
+
+	line := bench.Plot{Name: "construction", Curves: []bench.Curve{
+		{"min heap tree", "Construction", "heapTree", ""},
+	}}
+	svg.Reset()
+	drawn, err = line.WriteSVG(&svg, points)
+	assert.Nil(t, err)
+	assert.True(t, drawn)
+	assert.Contains(t, svg.String(), "<polyline")
+	assert.Contains(t, svg.String(), "nombre de clés")
+
+	empty := bench.Plot{Name: "empty", Curves: []bench.Curve{{"none", "Union", "heapTree", ""}}}
+	svg.Reset()
+	drawn, err = empty.WriteSVG(&svg, points)
+	assert.Nil(t, err)
+	assert.False(t, drawn)
+	assert.Equal(t, 0, svg.Len())
+}
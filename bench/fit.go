@@ -0,0 +1,216 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Model is a growth rate a benchmark is fitted against
+type Model int
+
+const (
+	Constant Model = iota
+	Logarithmic
+	Linear
+	Linearithmic
+)
+
+var Models = []Model{Constant, Logarithmic, Linear, Linearithmic}
+
+func (model Model) String() string {
+	switch model {
+	case Constant:
+		return "O(1)"
+	case Logarithmic:
+		return "O(log n)"
+	case Linear:
+		return "O(n)"
+	case Linearithmic:
+		return "O(n log n)"
+	}
+	return fmt.Sprintf("Model(%d)", int(model))
+}
+
+func (model Model) eval(n float64) float64 {
+	switch model {
+	case Logarithmic:
+		return math.Log2(n)
+	case Linear:
+		return n
+	case Linearithmic:
+		return n * math.Log2(n)
+	}
+	return 1
+}
+
+// Least number of sizes for a fit to mean anything
+const minFitSizes = 3
+
+// Fit is time = Coef * model(n), Error is the root mean square of the
+// residuals of log(time) so that small and large sizes weigh the same, an
+// error of 0.1 is about 10% off. Points is the number of points fitted.
+type Fit struct {
+	Model  Model
+	Coef   float64
+	Error  float64
+	Points int
+}
+
+// Fit the points against a single model, in log space the coefficient is
+// the mean distance between the times and the model. The points with a
+// time of 0, or where the model is 0 as log n at n = 1, have no logarithm
+// and are skipped.
+func FitModel(points []Point, model Model) (Fit, error) {
+	fit := Fit{Model: model}
+	residuals := make([]float64, 0, len(points))
+	logCoef := 0.0
+	for _, point := range points {
+		value := model.eval(float64(point.Size))
+		if !(point.TimeMs > 0) || !(value > 0) {
+			continue
+		}
+		residual := math.Log(point.TimeMs) - math.Log(value)
+		residuals = append(residuals, residual)
+		logCoef += residual
+	}
+	if len(residuals) < 2 {
+		return fit, fmt.Errorf("%v: %d usable points out of %d, at least 2 needed",
+			model, len(residuals), len(points))
+	}
+	fit.Points = len(residuals)
+	logCoef /= float64(len(residuals))
+	fit.Coef = math.Exp(logCoef)
+
+	for _, residual := range residuals {
+		fit.Error += (residual - logCoef) * (residual - logCoef)
+	}
+	fit.Error = math.Sqrt(fit.Error / float64(len(residuals)))
+	return fit, nil
+}
+
+// Return the fit of every model that can be fitted, the best one first
+func FitModels(points []Point) ([]Fit, error) {
+	fits := make([]Fit, 0, len(Models))
+	var err error
+	for _, model := range Models {
+		fit, fitErr := FitModel(points, model)
+		if fitErr != nil {
+			err = fitErr
+			continue
+		}
+		fits = append(fits, fit)
+	}
+	if len(fits) == 0 {
+		return nil, err
+	}
+	sort.SliceStable(fits, func(i, j int) bool {
+		return fits[i].Error < fits[j].Error
+	})
+	return fits, nil
+}
+
+/**
+ * Expectations
+ */
+
+// Expected growth of a benchmark for every implementation, or for a single
+// one when Impl is set
+type Expectation struct {
+	Benchmark string
+	Impl      string
+	Model     Model
+}
+
+// Theoretical complexities of the lib benchmarks, each one builds or merges
+// a whole heap so the growth is the one of the full operation on n keys
+var DefaultExpectations = []Expectation{
+	{"AjoutIteratif", "", Linearithmic},
+	{"Construction", "", Linear},
+	{"ConstructionParallel", "", Linear},
+	{"Union", "heapArray", Linear},
+	{"Union", "heapTree", Linear},
+	{"Union", "heapBinomial", Logarithmic},
+	{"SupprMinWords", "", Linearithmic},
+}
+
+func expectedModel(expectations []Expectation, benchmark string, impl string) (Model, bool) {
+	found, model := false, Constant
+	for _, expectation := range expectations {
+		if expectation.Benchmark != benchmark {
+			continue
+		}
+		if expectation.Impl == impl {
+			return expectation.Model, true
+		}
+		if expectation.Impl == "" {
+			found, model = true, expectation.Model
+		}
+	}
+	return model, found
+}
+
+// When the best fit grows faster than expected, the expected model must be
+// this much worse than the best one to report a regression, n and n log n
+// are hard to tell apart from noisy times on a few sizes
+const RegressionTolerance = 1.5
+
+// Past this error the expected model does not describe the times at all,
+// even if no other model does better
+const MaxFitError = 0.5
+
+// Complexity is the fit of one benchmark of one implementation
+type Complexity struct {
+	Benchmark string
+	Impl      string
+	Sizes     int
+	Best      Fit
+	// fit of the expected model, when there is one
+	Expected   *Fit
+	Regression bool
+}
+
+// Fit every benchmark and implementation with enough sizes, and compare the
+// best fit to the expectations; the points are sorted as Aggregate returns
+// them, the series no model can be fitted to are skipped
+func Analyze(points []Point, expectations []Expectation) []Complexity {
+	complexities := make([]Complexity, 0)
+	for start := 0; start < len(points); {
+		end := start
+		for end < len(points) && points[end].Benchmark == points[start].Benchmark &&
+			points[end].Impl == points[start].Impl {
+			end++
+		}
+		series := points[start:end]
+		start = end
+		if len(series) < minFitSizes {
+			continue
+		}
+
+		fits, err := FitModels(series)
+		if err != nil {
+			continue
+		}
+		complexity := Complexity{
+			Benchmark: series[0].Benchmark,
+			Impl:      series[0].Impl,
+			Sizes:     len(series),
+			Best:      fits[0],
+		}
+		if model, ok := expectedModel(expectations, complexity.Benchmark, complexity.Impl); ok {
+			for i := range fits {
+				if fits[i].Model == model {
+					complexity.Expected = &fits[i]
+				}
+			}
+			if complexity.Expected != nil {
+				expectedError := complexity.Expected.Error
+				complexity.Regression = complexity.Best.Model > model &&
+					(expectedError > complexity.Best.Error*RegressionTolerance ||
+						expectedError > MaxFitError)
+			}
+		}
+		complexities = append(complexities, complexity)
+	}
+	return complexities
+}
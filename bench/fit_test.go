@@ -0,0 +1,183 @@
+package bench_test
+
+import (
+	"arithmos/bench"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fitSizes = []int{1000, 5000, 10000, 50000, 120000, 500000, 1000000}
+
+func genPoints(benchmark string, impl string, time func(n float64) float64) []bench.Point {
+	points := make([]bench.Point, 0, len(fitSizes))
+	for i, size := range fitSizes {
+		// a little noise, alternating up and down
+		noise := 1 + 0.03*float64(1-2*(i%2))
+		points = append(points, bench.Point{
+			Benchmark: benchmark, Impl: impl, Size: size,
+			TimeMs: time(float64(size)) * noise, Runs: 1,
+		})
+	}
+	return points
+}
+
+func TestFitModels(t *testing.T) {
+	times := map[bench.Model]func(n float64) float64{
+		bench.Constant:     func(n float64) float64 { return 2 },
+		bench.Logarithmic:  func(n float64) float64 { return 0.01 * math.Log2(n) },
+		bench.Linear:       func(n float64) float64 { return 0.0001 * n },
+		bench.Linearithmic: func(n float64) float64 { return 0.00001 * n * math.Log2(n) },
+	}
+	for model, time := range times {
+		fits, err := bench.FitModels(genPoints("Op", "impl", time))
+		assert.NoError(t, err)
+		assert.Equal(t, model, fits[0].Model, model.String())
+		assert.Less(t, fits[0].Error, 0.05)
+		assert.Equal(t, len(bench.Models), len(fits))
+	}
+
+	fit, err := bench.FitModel(genPoints("Op", "impl", times[bench.Linear]), bench.Linear)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.0001, fit.Coef, 0.00001)
+	assert.Equal(t, len(fitSizes), fit.Points)
+}
+
+func TestFitModelsUnusablePoints(t *testing.T) {
+	points := genPoints("Op", "impl", func(n float64) float64 { return 0.0001 * n })
+	points = append([]bench.Point{
+		{Benchmark: "Op", Impl: "impl", Size: 1, TimeMs: 0.0001, Runs: 1},
+		{Benchmark: "Op", Impl: "impl", Size: 10, TimeMs: 0, Runs: 1},
+	}, points...)
+
+	// log n is 0 at n = 1 and a time of 0 has no logarithm
+	fits, err := bench.FitModels(points)
+	assert.NoError(t, err)
+	assert.Equal(t, bench.Linear, fits[0].Model)
+	for _, fit := range fits {
+		assert.False(t, math.IsNaN(fit.Error), fit.Model.String())
+	}
+	fit, err := bench.FitModel(points, bench.Linear)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fitSizes)+1, fit.Points)
+	fit, err = bench.FitModel(points, bench.Logarithmic)
+	assert.NoError(t, err)
+	assert.Equal(t, len(fitSizes), fit.Points)
+
+	_, err = bench.FitModel(points[:3], bench.Logarithmic)
+	assert.Error(t, err)
+	_, err = bench.FitModels([]bench.Point{{Size: 1000, TimeMs: 0}, {Size: 2000, TimeMs: 0}})
+	assert.Error(t, err)
+}
+
+func TestAnalyze(t *testing.T) {
+	points := make([]bench.Point, 0)
+	points = append(points, genPoints("Construction", "heapArray", func(n float64) float64 {
+		return 0.0001 * n
+	})...)
+	points = append(points, genPoints("Construction", "heapTree", func(n float64) float64 {
+		return 0.00001 * n * math.Log2(n)
+	})...)
+	points = append(points, genPoints("Union", "heapArray", func(n float64) float64 {
+		return 0.000001 * n * n
+	})...)
+	points = append(points, genPoints("Union", "heapBinomial", func(n float64) float64 {
+		return 0.01 * math.Log2(n)
+	})...)
+	points = append(points, genPoints("Unknown", "heapArray", func(n float64) float64 {
+		return 1
+	})...)
+	points = append(points, genPoints("Words", "heapArray", func(n float64) float64 {
+		return 1
+	})[:2]...)
+
+	complexities := bench.Analyze(points, bench.DefaultExpectations)
+	assert.Equal(t, 5, len(complexities))
+
+	assert.Equal(t, "Construction", complexities[0].Benchmark)
+	assert.Equal(t, bench.Linear, complexities[0].Best.Model)
+	assert.Equal(t, bench.Linear, complexities[0].Expected.Model)
+	assert.False(t, complexities[0].Regression)
+
+	assert.Equal(t, "heapTree", complexities[1].Impl)
+	assert.Equal(t, bench.Linearithmic, complexities[1].Best.Model)
+	assert.True(t, complexities[1].Regression)
+
+	// HeapArrayUnion is no longer linear, it is quadratic: no model fits
+	// but the linear one is way off
+	assert.Equal(t, "Union", complexities[2].Benchmark)
+	assert.Equal(t, bench.Linearithmic, complexities[2].Best.Model)
+	assert.Greater(t, complexities[2].Expected.Error, bench.MaxFitError)
+	assert.True(t, complexities[2].Regression)
+
+	assert.Equal(t, bench.Logarithmic, complexities[3].Best.Model)
+	assert.False(t, complexities[3].Regression)
+
+	assert.Equal(t, bench.Constant, complexities[4].Best.Model)
+	assert.Nil(t, complexities[4].Expected)
+	assert.False(t, complexities[4].Regression)
+}
+
+func TestAnalyzeBenchOutput(t *testing.T) {
+	f, err := os.Open("../bench_output")
+	assert.NoError(t, err)
+	defer f.Close()
+	results, err := bench.Parse(f)
+	assert.NoError(t, err)
+	points := bench.Aggregate(results)
+
+	find := func(complexities []bench.Complexity, benchmark string, impl string) bench.Complexity {
+		for _, complexity := range complexities {
+			if complexity.Benchmark == benchmark && complexity.Impl == impl {
+				return complexity
+			}
+		}
+		t.Fatalf("no complexity for %s %s", benchmark, impl)
+		return bench.Complexity{}
+	}
+
+	complexities := bench.Analyze(points, bench.DefaultExpectations)
+	assert.Equal(t, 8, len(complexities))
+	for _, complexity := range complexities {
+		assert.False(t, complexity.Regression, complexity.Benchmark+" "+complexity.Impl)
+		assert.False(t, math.IsNaN(complexity.Best.Error))
+	}
+
+	// the array construction leans to n log n on these sizes, but the linear
+	// fit is within the tolerance
+	construction := find(complexities, "Construction", "heapArray")
+	assert.Equal(t, bench.Linearithmic, construction.Best.Model)
+	assert.Equal(t, bench.Linear, construction.Expected.Model)
+	assert.Less(t, construction.Expected.Error, construction.Best.Error*bench.RegressionTolerance)
+
+	// growing slower than expected is never a regression
+	ajout := find(complexities, "AjoutIteratif", "heapTree")
+	assert.Equal(t, bench.Linear, ajout.Best.Model)
+	assert.Equal(t, bench.Linearithmic, ajout.Expected.Model)
+
+	// the same results, made quadratic, are flagged
+	slowed := make([]bench.Point, 0, len(points))
+	for _, point := range points {
+		if point.Benchmark == "Construction" && point.Impl == "heapArray" {
+			point.TimeMs *= float64(point.Size) / 1000
+		}
+		slowed = append(slowed, point)
+	}
+	construction = find(bench.Analyze(slowed, bench.DefaultExpectations), "Construction", "heapArray")
+	assert.True(t, construction.Regression)
+	assert.Greater(t, construction.Expected.Error, bench.MaxFitError)
+
+	// and so is a time of 0 at the smallest size, which is skipped
+	zeroed := make([]bench.Point, 0, len(slowed))
+	for _, point := range slowed {
+		if point.Benchmark == "Construction" && point.Impl == "heapArray" && point.Size == 1000 {
+			point.TimeMs = 0
+		}
+		zeroed = append(zeroed, point)
+	}
+	construction = find(bench.Analyze(zeroed, bench.DefaultExpectations), "Construction", "heapArray")
+	assert.True(t, construction.Regression)
+	assert.Equal(t, construction.Sizes-1, construction.Best.Points)
+}
@@ -0,0 +1,99 @@
+// Package bench reads the output of the lib benchmarks and turns it into
+// tables and charts
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Result is a single benchmark line, for example
+// BenchmarkConstruction/heapTree/jeu_2_nb_cles_1000.txt-8  17772  67403 ns/op
+type Result struct {
+	// Benchmark function without its prefix, Construction
+	Benchmark string
+	// Implementation under test, heapTree
+	Impl string
+	// Name of the input, jeu_2_nb_cles_1000.txt
+	Dataset string
+	// Number of keys of the input, 1000
+	Size int
+	// Number of the cles_alea file, 0 when generated or not from cles_alea
+	Jeu        int
+	Iterations int
+	NsPerOp    float64
+	// Other values of the line, like B/op or the ones of b.ReportMetric
+	Metrics map[string]float64
+}
+
+var (
+	sizePattern = regexp.MustCompile(`cles_(\d+)`)
+	jeuPattern  = regexp.MustCompile(`^jeu_(\d+)_`)
+	procsSuffix = regexp.MustCompile(`-\d+$`)
+)
+
+// Parse a single benchmark line, ok is false for any other line
+func ParseLine(line string) (result Result, ok bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return result, false, nil
+	}
+
+	name := procsSuffix.ReplaceAllString(strings.TrimPrefix(fields[0], "Benchmark"), "")
+	parts := strings.SplitN(name, "/", 3)
+	result.Benchmark = parts[0]
+	if len(parts) > 1 {
+		result.Impl = parts[1]
+	}
+	if len(parts) > 2 {
+		result.Dataset = parts[2]
+	}
+
+	if match := sizePattern.FindStringSubmatch(result.Dataset); match != nil {
+		result.Size, _ = strconv.Atoi(match[1])
+	}
+	if match := jeuPattern.FindStringSubmatch(result.Dataset); match != nil {
+		result.Jeu, _ = strconv.Atoi(match[1])
+	}
+
+	result.Iterations, err = strconv.Atoi(fields[1])
+	if err != nil {
+		return result, false, fmt.Errorf("iterations of %s: %v", fields[0], err)
+	}
+
+	result.Metrics = make(map[string]float64)
+	for i := 2; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return result, false, fmt.Errorf("%s of %s: %v", fields[i+1], fields[0], err)
+		}
+		if fields[i+1] == "ns/op" {
+			result.NsPerOp = value
+		} else {
+			result.Metrics[fields[i+1]] = value
+		}
+	}
+
+	return result, true, nil
+}
+
+// Parse every benchmark line of a go test -bench output, the other lines
+// (goos, PASS, ...) are skipped
+func Parse(r io.Reader) ([]Result, error) {
+	results := make([]Result, 0)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		result, ok, err := ParseLine(s.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if ok {
+			results = append(results, result)
+		}
+	}
+	return results, s.Err()
+}
@@ -0,0 +1,191 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Point is the mean time of a benchmark over every input of the same size,
+// the cles_alea files jeu_1 to jeu_5 and the generated ones alike
+type Point struct {
+	Benchmark string  `json:"benchmark"`
+	Impl      string  `json:"impl"`
+	Size      int     `json:"size"`
+	TimeMs    float64 `json:"time_ms"`
+	// number of results averaged
+	Runs int `json:"runs"`
+}
+
+// Average the results per benchmark, implementation and size, the results
+// without a size are dropped
+func Aggregate(results []Result) []Point {
+	type group struct {
+		benchmark, impl string
+		size            int
+	}
+	sums := make(map[group]*Point)
+
+	for _, result := range results {
+		if result.Size == 0 {
+			continue
+		}
+		key := group{result.Benchmark, result.Impl, result.Size}
+		point, ok := sums[key]
+		if !ok {
+			point = &Point{Benchmark: result.Benchmark, Impl: result.Impl, Size: result.Size}
+			sums[key] = point
+		}
+		point.TimeMs += result.NsPerOp / 1e6
+		point.Runs++
+	}
+
+	points := make([]Point, 0, len(sums))
+	for _, point := range sums {
+		point.TimeMs /= float64(point.Runs)
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		a, b := points[i], points[j]
+		if a.Benchmark != b.Benchmark {
+			return a.Benchmark < b.Benchmark
+		}
+		if a.Impl != b.Impl {
+			return a.Impl < b.Impl
+		}
+		return a.Size < b.Size
+	})
+
+	return points
+}
+
+// Return the points of one benchmark and implementation, by increasing size
+func Select(points []Point, benchmark string, impl string) []Point {
+	selected := make([]Point, 0)
+	for _, point := range points {
+		if point.Benchmark == benchmark && point.Impl == impl {
+			selected = append(selected, point)
+		}
+	}
+	return selected
+}
+
+func WriteCSV(w io.Writer, points []Point) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"benchmark", "impl", "size", "time_ms", "runs"})
+	for _, point := range points {
+		out.Write([]string{
+			point.Benchmark,
+			point.Impl,
+			strconv.Itoa(point.Size),
+			strconv.FormatFloat(point.TimeMs, 'f', -1, 64),
+			strconv.Itoa(point.Runs),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func WriteJSON(w io.Writer, points []Point) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(points)
+}
+
+/**
+ * Plots
+ */
+
+// Curve of a plot, Subtract removes the time of another benchmark of the
+// same implementation and size, like the construction done before SupprMin
+type Curve struct {
+	Label     string
+	Benchmark string
+	Impl      string
+	Subtract  string
+}
+
+// Plot is a chart written to Name.svg, sizes on the x axis and times on the y one
+type Plot struct {
+	Name   string
+	Bars   bool
+	Curves []Curve
+}
+
+var (
+	heapCurves = func(benchmark string) []Curve {
+		return []Curve{
+			{"min heap binomial", benchmark, "heapBinomial", ""},
+			{"min heap tree", benchmark, "heapTree", ""},
+			{"min heap array", benchmark, "heapArray", ""},
+		}
+	}
+
+	// Same charts as the ones of the plots directory
+	DefaultPlots = []Plot{
+		{Name: "ajout_tree", Curves: []Curve{
+			{"ajout min heap tree", "AjoutIteratif", "heapTree", ""},
+			{"construction min heap tree", "Construction", "heapTree", ""},
+		}},
+		{Name: "ajout_array", Curves: []Curve{
+			{"ajout min heap array", "AjoutIteratif", "heapArray", ""},
+			{"construction min heap array", "Construction", "heapArray", ""},
+		}},
+		{Name: "construction", Curves: heapCurves("Construction")},
+		{Name: "heap_union", Curves: heapCurves("Union")},
+		{Name: "heap_binomial_union", Curves: heapCurves("Union")[:1]},
+		{Name: "words_ajout", Bars: true, Curves: heapCurves("AjoutWords")},
+		{Name: "words_construction", Bars: true, Curves: heapCurves("ConstructionWords")},
+		{Name: "words_supprmin", Bars: true, Curves: []Curve{
+			{"min heap binomial", "SupprMinWords", "heapBinomial", "ConstructionWords"},
+			{"min heap tree", "SupprMinWords", "heapTree", "ConstructionWords"},
+			{"min heap array", "SupprMinWords", "heapArray", "ConstructionWords"},
+		}},
+		{Name: "words_union", Bars: true, Curves: heapCurves("UnionWords")},
+	}
+)
+
+// Series is a curve resolved against the aggregated points
+type Series struct {
+	Label  string
+	Points []Point
+}
+
+// Resolve the curves of the plot, the ones without any point are left out
+func (plot *Plot) Series(points []Point) []Series {
+	series := make([]Series, 0, len(plot.Curves))
+	for _, curve := range plot.Curves {
+		selected := Select(points, curve.Benchmark, curve.Impl)
+		if curve.Subtract != "" {
+			base := Select(points, curve.Subtract, curve.Impl)
+			kept := make([]Point, 0, len(selected))
+			for _, point := range selected {
+				for _, basePoint := range base {
+					if basePoint.Size == point.Size {
+						point.TimeMs -= basePoint.TimeMs
+						kept = append(kept, point)
+					}
+				}
+			}
+			selected = kept
+		}
+		if len(selected) > 0 {
+			series = append(series, Series{Label: curve.Label, Points: selected})
+		}
+	}
+	return series
+}
+
+// Write the chart of the plot, false when there was nothing to draw
+func (plot *Plot) WriteSVG(w io.Writer, points []Point) (bool, error) {
+	series := plot.Series(points)
+	if len(series) == 0 {
+		return false, nil
+	}
+	if plot.Bars {
+		return true, writeBarChart(w, series)
+	}
+	return true, writeLineChart(w, series)
+}
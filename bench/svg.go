@@ -0,0 +1,200 @@
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 500
+	// room around the drawing area for the axes and the legend
+	chartLeft   = 80
+	chartRight  = 20
+	chartTop    = 20
+	chartBottom = 60
+
+	xLabel = "nombre de clés"
+	yLabel = "Temps (ms)"
+)
+
+var palette = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+// Return about count round values covering [0, max]
+func ticks(max float64, count int) []float64 {
+	if max <= 0 {
+		return []float64{0}
+	}
+	raw := max / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, factor := range []float64{1, 2, 5, 10} {
+		step = factor * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	values := make([]float64, 0, count+2)
+	for value := 0.0; value < max+step; value += step {
+		values = append(values, value)
+	}
+	return values
+}
+
+func formatTick(value float64) string {
+	return strconv.FormatFloat(value, 'g', 6, 64)
+}
+
+type chart struct {
+	out    *bufio.Writer
+	yTicks []float64
+}
+
+func newChart(w io.Writer, maxTime float64) *chart {
+	c := &chart{out: bufio.NewWriter(w), yTicks: ticks(maxTime, 8)}
+	fmt.Fprintf(c.out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" "+
+		"viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"12\">\n",
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(c.out, "<rect width=\"%d\" height=\"%d\" fill=\"white\"/>\n", chartWidth, chartHeight)
+	return c
+}
+
+func (c *chart) y(value float64) float64 {
+	top := c.yTicks[len(c.yTicks)-1]
+	if top == 0 {
+		top = 1
+	}
+	return chartHeight - chartBottom - value/top*(chartHeight-chartTop-chartBottom)
+}
+
+// Draw the y axis with its grid and both axis labels
+func (c *chart) axes() {
+	for _, tick := range c.yTicks {
+		fmt.Fprintf(c.out, "<line x1=\"%d\" y1=\"%.1f\" x2=\"%d\" y2=\"%.1f\" stroke=\"#ddd\"/>\n",
+			chartLeft, c.y(tick), chartWidth-chartRight, c.y(tick))
+		fmt.Fprintf(c.out, "<text x=\"%d\" y=\"%.1f\" text-anchor=\"end\">%s</text>\n",
+			chartLeft-6, c.y(tick)+4, formatTick(tick))
+	}
+	fmt.Fprintf(c.out, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\"/>\n",
+		chartLeft, chartTop, chartLeft, chartHeight-chartBottom)
+	fmt.Fprintf(c.out, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"black\"/>\n",
+		chartLeft, chartHeight-chartBottom, chartWidth-chartRight, chartHeight-chartBottom)
+	fmt.Fprintf(c.out, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+		(chartLeft+chartWidth-chartRight)/2, chartHeight-15, html.EscapeString(xLabel))
+	fmt.Fprintf(c.out, "<text x=\"20\" y=\"%d\" text-anchor=\"middle\" "+
+		"transform=\"rotate(-90 20 %d)\">%s</text>\n",
+		chartHeight/2, chartHeight/2, html.EscapeString(yLabel))
+}
+
+func (c *chart) legend(series []Series) {
+	for i, s := range series {
+		y := chartTop + 10 + i*18
+		fmt.Fprintf(c.out, "<rect x=\"%d\" y=\"%d\" width=\"14\" height=\"4\" fill=\"%s\"/>\n",
+			chartLeft+16, y-4, palette[i%len(palette)])
+		fmt.Fprintf(c.out, "<text x=\"%d\" y=\"%d\">%s</text>\n",
+			chartLeft+36, y, html.EscapeString(s.Label))
+	}
+}
+
+func (c *chart) close() error {
+	c.out.WriteString("</svg>\n")
+	return c.out.Flush()
+}
+
+func maxTime(series []Series) float64 {
+	max := 0.0
+	for _, s := range series {
+		for _, point := range s.Points {
+			max = math.Max(max, point.TimeMs)
+		}
+	}
+	return max
+}
+
+// Draw one line per series, sizes on a linear x axis
+func writeLineChart(w io.Writer, series []Series) error {
+	maxSize := 0
+	for _, s := range series {
+		for _, point := range s.Points {
+			if point.Size > maxSize {
+				maxSize = point.Size
+			}
+		}
+	}
+	xTicks := ticks(float64(maxSize), 6)
+	xTop := xTicks[len(xTicks)-1]
+	if xTop == 0 {
+		xTop = 1
+	}
+	x := func(size int) float64 {
+		return chartLeft + float64(size)/xTop*(chartWidth-chartLeft-chartRight)
+	}
+
+	c := newChart(w, maxTime(series))
+	c.axes()
+	for _, tick := range xTicks {
+		fmt.Fprintf(c.out, "<text x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+			x(int(tick)), chartHeight-chartBottom+18, formatTick(tick))
+	}
+
+	for i, s := range series {
+		color := palette[i%len(palette)]
+		fmt.Fprintf(c.out, "<polyline fill=\"none\" stroke=\"%s\" stroke-width=\"2\" points=\"", color)
+		for _, point := range s.Points {
+			fmt.Fprintf(c.out, "%.1f,%.1f ", x(point.Size), c.y(point.TimeMs))
+		}
+		c.out.WriteString("\"/>\n")
+		for _, point := range s.Points {
+			fmt.Fprintf(c.out, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"3\" fill=\"%s\"/>\n",
+				x(point.Size), c.y(point.TimeMs), color)
+		}
+	}
+	c.legend(series)
+
+	return c.close()
+}
+
+// Draw one group of bars per size, one bar per series in each group
+func writeBarChart(w io.Writer, series []Series) error {
+	sizes := make([]int, 0)
+	for _, s := range series {
+		for _, point := range s.Points {
+			found := false
+			for _, size := range sizes {
+				found = found || size == point.Size
+			}
+			if !found {
+				sizes = append(sizes, point.Size)
+			}
+		}
+	}
+
+	c := newChart(w, maxTime(series))
+	c.axes()
+
+	groupWidth := float64(chartWidth-chartLeft-chartRight) / float64(len(sizes))
+	barWidth := groupWidth * 0.8 / float64(len(series))
+	for g, size := range sizes {
+		groupLeft := chartLeft + float64(g)*groupWidth + groupWidth*0.1
+		fmt.Fprintf(c.out, "<text x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%d</text>\n",
+			groupLeft+groupWidth*0.4, chartHeight-chartBottom+18, size)
+		for i, s := range series {
+			for _, point := range s.Points {
+				if point.Size != size {
+					continue
+				}
+				fmt.Fprintf(c.out, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+					groupLeft+float64(i)*barWidth+barWidth*0.05, c.y(point.TimeMs),
+					barWidth*0.9, math.Max(c.y(0)-c.y(point.TimeMs), 0), palette[i%len(palette)])
+			}
+		}
+	}
+	c.legend(series)
+
+	return c.close()
+}
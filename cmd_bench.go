@@ -0,0 +1,97 @@
+package main
+
+import (
+	"arithmos/bench"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Run the lib benchmarks, saving their raw output to path
+func runBenchmarks(path string, pattern string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmd := exec.Command("go", "test", "./lib", "-run", "^$", "-bench", pattern)
+	cmd.Stdout = io.MultiWriter(f, os.Stdout)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	input := flags.String("input", "bench_output", "output of go test -bench to read")
+	run := flags.Bool("run", false, "run the benchmarks first and save their output to -input")
+	pattern := flags.String("bench", ".", "benchmarks to run with -run")
+	out := flags.String("out", "plots", "directory of the csv, json and svg files")
+	flags.Parse(args)
+
+	if *run {
+		if err := runBenchmarks(*input, *pattern); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	results, err := bench.Parse(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", *input, err)
+	}
+	points := bench.Aggregate(results)
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+	err = writeFile(filepath.Join(*out, "bench.csv"), func(w io.Writer) error {
+		return bench.WriteCSV(w, points)
+	})
+	if err != nil {
+		return err
+	}
+	err = writeFile(filepath.Join(*out, "bench.json"), func(w io.Writer) error {
+		return bench.WriteJSON(w, points)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, plot := range bench.DefaultPlots {
+		plot := plot
+		path := filepath.Join(*out, plot.Name+".svg")
+		drawn := false
+		err := writeFile(path, func(w io.Writer) error {
+			drawn, err = plot.WriteSVG(w, points)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !drawn {
+			os.Remove(path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"arithmos/bench"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func runComplexity(args []string) error {
+	flags := flag.NewFlagSet("complexity", flag.ExitOnError)
+	input := flags.String("input", "bench_output", "output of go test -bench to read")
+	flags.Parse(args)
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	results, err := bench.Parse(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %v", *input, err)
+	}
+	complexities := bench.Analyze(bench.Aggregate(results), bench.DefaultExpectations)
+
+	out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "benchmark\timpl\tsizes\tbest fit\terror\texpected\terror\t")
+	regressions := 0
+	for _, complexity := range complexities {
+		expected, expectedError, status := "-", "-", ""
+		if complexity.Expected != nil {
+			expected = complexity.Expected.Model.String()
+			expectedError = fmt.Sprintf("%.3f", complexity.Expected.Error)
+		}
+		if complexity.Regression {
+			status = "REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(out, "%s\t%s\t%d\t%v\t%.3f\t%s\t%s\t%s\n",
+			complexity.Benchmark, complexity.Impl, complexity.Sizes,
+			complexity.Best.Model, complexity.Best.Error, expected, expectedError, status)
+	}
+	out.Flush()
+
+	if regressions > 0 {
+		return fmt.Errorf("%d benchmarks grow faster than expected", regressions)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"arithmos/lib"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ExitOnError)
+	out := flags.String("out", "", "directory of the binary files, the one of each input with a _bin suffix by default")
+	bigEndian := flags.Bool("big-endian", false, "write the numbers in big endian order")
+	checksum := flags.Bool("checksum", false, "append a checksum of the keys")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: arithmos convert [flags] <key file>...")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	opts := lib.BinaryKeyOptions{Checksum: *checksum}
+	if *bigEndian {
+		opts.ByteOrder = binary.BigEndian
+	}
+
+	for _, path := range flags.Args() {
+		keys, err := readKeyFile(path)
+		if err != nil {
+			return err
+		}
+
+		// apart from the text files, so that a directory of keys holds a
+		// single format and is not benchmarked twice
+		dir := *out
+		if dir == "" {
+			dir = filepath.Clean(filepath.Dir(path)) + "_bin"
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".txt") + lib.BinaryKeyExt
+		binPath := filepath.Join(dir, name)
+
+		f, err := os.Create(binPath)
+		if err != nil {
+			return err
+		}
+		err = lib.WriteBinaryKeys(f, keys, opts)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", binPath, err)
+		}
+	}
+
+	return nil
+}
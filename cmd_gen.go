@@ -0,0 +1,54 @@
+package main
+
+import (
+	"arithmos/lib"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	sizes := flags.String("n", "1000", "comma separated numbers of keys, one file per number")
+	sets := flags.Int("sets", 1, "number of files per number of keys, jeu_1 to jeu_<sets>")
+	distName := flags.String("dist", "uniform",
+		"key distribution: uniform, sorted, reverse, nearly-sorted, duplicates, clustered or zipfian")
+	seed := flags.Int64("seed", 1, "seed of the first file, the others derive from it")
+	out := flags.String("out", ".", "directory of the generated files")
+	flags.Parse(args)
+
+	dist, err := lib.ParseKeyDistribution(*distName)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	for _, size := range strings.Split(*sizes, ",") {
+		nbKeys, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil || nbKeys < 0 {
+			return fmt.Errorf("invalid number of keys %q", size)
+		}
+		for jeu := 1; jeu <= *sets; jeu++ {
+			keys := lib.GenKeys(nbKeys, dist, *seed+int64(jeu-1)<<32+int64(nbKeys))
+			path := filepath.Join(*out, lib.KeyFileName(jeu, nbKeys))
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			err = lib.WriteKeys(f, keys)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"arithmos/lib"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+type prettyPrinter interface {
+	Pretty(w io.Writer, opts lib.PrettyOptions) error
+}
+
+func runPretty(args []string) error {
+	flags := flag.NewFlagSet("pretty", flag.ExitOnError)
+	structure := flags.String("s", "array",
+		"structure to draw: array, max, minmax, tree, binomial, search, treap or splay")
+	depth := flags.Int("depth", 4, "number of levels drawn, 0 for all")
+	keyLen := flags.Int("keylen", 0, "truncate the keys to this many characters")
+	hex := flags.Bool("hex", false, "draw the keys in hexadecimal")
+	ascii := flags.Bool("ascii", false, "draw with ASCII characters only")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: arithmos pretty [flags] <key file>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	keys, err := readKeyFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var printer prettyPrinter
+	switch *structure {
+	case "array":
+		heap := lib.NewMinHeapArray()
+		heap.Construction(keys)
+		printer = heap
+	case "max":
+		heap := lib.NewMaxHeapArray()
+		heap.Construction(keys)
+		printer = heap
+	case "minmax":
+		heap := lib.NewMinMaxHeapArray()
+		heap.Construction(keys)
+		printer = heap
+	case "tree":
+		heap := lib.NewMinHeapTree()
+		heap.Construction(keys)
+		printer = heap
+	case "binomial":
+		heap := lib.NewMinHeapBinomial()
+		heap.Construction(keys)
+		printer = heap
+	case "search":
+		tree := lib.NewSearchTree()
+		for _, key := range keys {
+			tree.Insert(key)
+		}
+		printer = tree
+	case "treap":
+		treap := lib.NewTreap()
+		for _, key := range keys {
+			treap.Insert(key)
+		}
+		printer = treap
+	case "splay":
+		tree := lib.NewSplayTree()
+		for _, key := range keys {
+			tree.Insert(key)
+		}
+		printer = tree
+	default:
+		return fmt.Errorf("unknown structure %q", *structure)
+	}
+
+	opts := lib.PrettyOptions{MaxDepth: *depth, MaxKeyLen: *keyLen, ASCII: *ascii}
+	if *hex {
+		opts.Format = lib.KeyHex
+	}
+	return printer.Pretty(os.Stdout, opts)
+}
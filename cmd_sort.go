@@ -0,0 +1,55 @@
+package main
+
+import (
+	"arithmos/lib"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Parse a number of bytes with an optional K, M or G suffix
+func parseSize(text string) (int64, error) {
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(text, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(text, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(text, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		text = text[:len(text)-1]
+	}
+	size, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+	return size * multiplier, nil
+}
+
+func runSort(args []string) error {
+	flags := flag.NewFlagSet("sort", flag.ExitOnError)
+	memory := flags.String("memory", "64M", "memory budget of the keys, with a K, M or G suffix")
+	tempDir := flags.String("tmp", "", "directory of the temporary runs, the one of the output by default")
+	maxRuns := flags.Int("runs", lib.DefaultMaxOpenRuns, "greatest number of runs merged at once")
+	binary := flags.Bool("binary", false, "write a binary key file, always the case for a binary input")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: arithmos sort [flags] <key file> <sorted key file>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	budget, err := parseSize(*memory)
+	if err != nil {
+		return err
+	}
+	opts := lib.ExternalSortOptions{Memory: budget, TempDir: *tempDir, MaxOpenRuns: *maxRuns}
+	return lib.ExternalSortFile(flags.Arg(0), flags.Arg(1), *binary, opts)
+}
@@ -3,7 +3,6 @@ module arithmos
 go 1.20
 
 require (
-	github.com/bradleyjkemp/memviz v0.2.3
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/exp v0.0.0-20231206192017-f3f8817b8deb
 )
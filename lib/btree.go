@@ -0,0 +1,455 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/slices"
+)
+
+type bTreeNode struct {
+	// the keys themselves, not pointers, in increasing order
+	keys []KeyInt
+	// one more child than keys, the keys of children[i] are within
+	// [keys[i-1], keys[i]]; nil for a leaf
+	children []*bTreeNode
+}
+
+func (node *bTreeNode) isLeaf() bool {
+	return node.children == nil
+}
+
+// Index of the first key of the node not inferior to the key
+func (node *bTreeNode) lowerBound(key *KeyInt) int {
+	lo, hi := 0, len(node.keys)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if node.keys[mid].Inf(key) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+// Index of the first key of the node superior to the key
+func (node *bTreeNode) upperBound(key *KeyInt) int {
+	lo, hi := 0, len(node.keys)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if key.Inf(&node.keys[mid]) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo
+}
+
+/*
+BTree is a search tree whose nodes hold between degree-1 and 2*degree-1
+keys side by side, the root excepted, and all its leaves are at the same
+level. A search reads log(n)/log(degree) nodes, each one a few cache lines
+of keys, where a SearchTree follows a pointer to a node and one to its key
+per level.
+
+The keys are copied into the tree, and copied again out of it: the keys
+returned by Get, Ascend and Range are equal to the inserted ones but are
+not the same pointers, and stay valid whatever the tree does next.
+*/
+type BTree struct {
+	root   *bTreeNode
+	degree int
+	size   int
+}
+
+// Return an empty B-tree of the given minimum degree, at least 2
+func NewBTree(degree int) *BTree {
+	if degree < 2 {
+		panic(fmt.Sprintf("B-tree degree %d is inferior to 2", degree))
+	}
+	tree := &BTree{degree: degree}
+	tree.root = tree.newNode(true)
+	return tree
+}
+
+// Minimum degree of the tree
+func (tree *BTree) Degree() int {
+	return tree.degree
+}
+
+func (tree *BTree) maxKeys() int {
+	return 2*tree.degree - 1
+}
+
+// Return an empty node with room for its greatest number of keys
+func (tree *BTree) newNode(leaf bool) *bTreeNode {
+	node := &bTreeNode{keys: make([]KeyInt, 0, tree.maxKeys())}
+	if !leaf {
+		node.children = make([]*bTreeNode, 0, tree.maxKeys()+1)
+	}
+	return node
+}
+
+// Split the full child i of the node in two around its median key, which
+// moves up to the node
+func (tree *BTree) splitChild(node *bTreeNode, i int) {
+	t := tree.degree
+	child := node.children[i]
+	right := tree.newNode(child.isLeaf())
+	right.keys = append(right.keys, child.keys[t:]...)
+	if !child.isLeaf() {
+		right.children = append(right.children, child.children[t:]...)
+		for j := t; j < len(child.children); j++ {
+			child.children[j] = nil
+		}
+		child.children = child.children[:t]
+	}
+
+	node.keys = slices.Insert(node.keys, i, child.keys[t-1])
+	node.children = slices.Insert(node.children, i+1, right)
+	child.keys = child.keys[:t-1]
+}
+
+/*
+Insert
+
+ 1. When the root is full, split it under a new root, the tree grows by
+    one level.
+ 2. Walk down to the leaf where the key belongs, after the keys equal to
+    it, splitting every full node on the way so that its parent has room
+    for its median key.
+ 3. Insert the key in the leaf.
+*/
+func (tree *BTree) Insert(key *KeyInt) {
+	// work on a copy, the caller's key may change while the splits run
+	k := *key
+	key = &k
+	if len(tree.root.keys) == tree.maxKeys() {
+		root := tree.newNode(false)
+		root.children = append(root.children, tree.root)
+		tree.splitChild(root, 0)
+		tree.root = root
+	}
+
+	node := tree.root
+	for {
+		i := node.upperBound(key)
+		if node.isLeaf() {
+			node.keys = slices.Insert(node.keys, i, *key)
+			break
+		}
+		if len(node.children[i].keys) == tree.maxKeys() {
+			tree.splitChild(node, i)
+			if !key.Inf(&node.keys[i]) {
+				i++
+			}
+		}
+		node = node.children[i]
+	}
+	tree.size++
+}
+
+// Return a copy of a key equal to the given one, nil when missing
+func (tree *BTree) Get(key *KeyInt) *KeyInt {
+	node := tree.root
+	for {
+		i := node.lowerBound(key)
+		if i < len(node.keys) && node.keys[i].Eq(key) {
+			// the slot moves with the next Insert or Delete
+			found := node.keys[i]
+			return &found
+		}
+		if node.isLeaf() {
+			return nil
+		}
+		node = node.children[i]
+	}
+}
+
+/*
+Delete
+
+Remove a key equal to the given one and return whether there was one. The
+walk down only enters a node with at least degree keys, so that a key can
+be removed from it without going under degree-1;
+
+ 1. A key of a leaf is removed from it.
+ 2. A key of an internal node is replaced by its predecessor or its
+    successor when the child holding it has degree keys, which is removed
+    from that child; otherwise both children around the key are merged
+    with it and the key is removed from the merged child.
+ 3. Before entering a child with degree-1 keys, it takes a key from a
+    sibling with degree keys through the parent, or is merged with a
+    sibling and the key of the parent between them.
+ 4. When the root loses its last key, its only child becomes the root, the
+    tree shrinks by one level.
+*/
+func (tree *BTree) Delete(key *KeyInt) bool {
+	// work on a copy, the walk replaces keys of the nodes it goes through
+	k := *key
+	deleted := tree.deleteNode(tree.root, &k)
+	if len(tree.root.keys) == 0 && !tree.root.isLeaf() {
+		tree.root = tree.root.children[0]
+	}
+	if deleted {
+		tree.size--
+	}
+	return deleted
+}
+
+func (tree *BTree) deleteNode(node *bTreeNode, key *KeyInt) bool {
+	t := tree.degree
+	for {
+		i := node.lowerBound(key)
+		if i < len(node.keys) && node.keys[i].Eq(key) {
+			if node.isLeaf() {
+				node.keys = slices.Delete(node.keys, i, i+1)
+				return true
+			}
+			left, right := node.children[i], node.children[i+1]
+			switch {
+			case len(left.keys) >= t:
+				k := *tree.maxKey(left)
+				node.keys[i] = k
+				key, node = &k, left
+			case len(right.keys) >= t:
+				k := *tree.minKey(right)
+				node.keys[i] = k
+				key, node = &k, right
+			default:
+				tree.merge(node, i)
+				node = left
+			}
+			continue
+		}
+		if node.isLeaf() {
+			return false
+		}
+		if len(node.children[i].keys) < t {
+			i = tree.fill(node, i)
+		}
+		node = node.children[i]
+	}
+}
+
+func (tree *BTree) minKey(node *bTreeNode) *KeyInt {
+	for !node.isLeaf() {
+		node = node.children[0]
+	}
+	return &node.keys[0]
+}
+
+func (tree *BTree) maxKey(node *bTreeNode) *KeyInt {
+	for !node.isLeaf() {
+		node = node.children[len(node.children)-1]
+	}
+	return &node.keys[len(node.keys)-1]
+}
+
+// Merge the child i+1 of the node and the key i into the child i
+func (tree *BTree) merge(node *bTreeNode, i int) {
+	left, right := node.children[i], node.children[i+1]
+	left.keys = append(append(left.keys, node.keys[i]), right.keys...)
+	if !left.isLeaf() {
+		left.children = append(left.children, right.children...)
+	}
+	node.keys = slices.Delete(node.keys, i, i+1)
+	node.children = slices.Delete(node.children, i+1, i+2)
+}
+
+// Give degree keys to the child i of the node, which has one less; return
+// the index of the child now holding its keys
+func (tree *BTree) fill(node *bTreeNode, i int) int {
+	t := tree.degree
+	child := node.children[i]
+	switch {
+	case i > 0 && len(node.children[i-1].keys) >= t:
+		// the greatest key of the left sibling goes up, the parent key down
+		left := node.children[i-1]
+		child.keys = slices.Insert(child.keys, 0, node.keys[i-1])
+		node.keys[i-1] = left.keys[len(left.keys)-1]
+		left.keys = left.keys[:len(left.keys)-1]
+		if !child.isLeaf() {
+			last := len(left.children) - 1
+			child.children = slices.Insert(child.children, 0, left.children[last])
+			left.children[last] = nil
+			left.children = left.children[:last]
+		}
+		return i
+	case i < len(node.keys) && len(node.children[i+1].keys) >= t:
+		// the smallest key of the right sibling goes up, the parent key down
+		right := node.children[i+1]
+		child.keys = append(child.keys, node.keys[i])
+		node.keys[i] = right.keys[0]
+		right.keys = slices.Delete(right.keys, 0, 1)
+		if !child.isLeaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+		return i
+	case i < len(node.keys):
+		tree.merge(node, i)
+		return i
+	default:
+		tree.merge(node, i-1)
+		return i - 1
+	}
+}
+
+// Number of keys of the tree, equal keys included
+func (tree *BTree) Len() int {
+	return tree.size
+}
+
+// Call yield on every key in increasing order until it returns false
+func (tree *BTree) Ascend(yield func(key *KeyInt) bool) {
+	tree.Range(nil, nil, yield)
+}
+
+// Call yield on every key within [lower, upper) in increasing order until it
+// returns false, nil bounds are unbounded
+func (tree *BTree) Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool) {
+	tree.rangeNode(tree.root, lower, upper, yield)
+}
+
+// Return false once the range is done or yield returned false
+func (tree *BTree) rangeNode(node *bTreeNode, lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool) bool {
+	i := 0
+	if lower != nil {
+		// the children before i only hold keys below the range
+		i = node.lowerBound(lower)
+	}
+	for ; i <= len(node.keys); i++ {
+		if !node.isLeaf() && !tree.rangeNode(node.children[i], lower, upper, yield) {
+			return false
+		}
+		if i == len(node.keys) {
+			break
+		}
+		if upper != nil && !node.keys[i].Inf(upper) {
+			return false
+		}
+		key := node.keys[i]
+		if !yield(&key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Number of levels of nodes, the root being a leaf when the tree is empty
+func (tree *BTree) height() int {
+	height := 1
+	for node := tree.root; !node.isLeaf(); node = node.children[0] {
+		height++
+	}
+	return height
+}
+
+// Number of levels of nodes, all the leaves being on the last one
+func (tree *BTree) MaxLevel() int {
+	if tree.size == 0 {
+		return 0
+	}
+	return tree.height()
+}
+
+// Check that the keys of the node are in increasing order and within
+// [lower, upper], nil bounds are unbounded, that it holds enough keys and
+// that its leaves are at the given depth; return its number of keys
+func (tree *BTree) validateNode(node *bTreeNode, lower *KeyInt, upper *KeyInt, depth int) (int, error) {
+	if node != tree.root && len(node.keys) < tree.degree-1 {
+		return 0, fmt.Errorf("node has %d keys, less than %d", len(node.keys), tree.degree-1)
+	}
+	if len(node.keys) > tree.maxKeys() {
+		return 0, fmt.Errorf("node of %v has %d keys, more than %d", &node.keys[0], len(node.keys), tree.maxKeys())
+	}
+	for i := range node.keys {
+		key := &node.keys[i]
+		if lower != nil && key.Inf(lower) {
+			return 0, fmt.Errorf("%v is after %v", key, lower)
+		}
+		if upper != nil && upper.Inf(key) {
+			return 0, fmt.Errorf("%v is before %v", key, upper)
+		}
+		lower = key
+	}
+
+	if node.isLeaf() {
+		if depth != 1 {
+			return 0, fmt.Errorf("leaf of %d keys is %d levels above the others", len(node.keys), depth-1)
+		}
+		return len(node.keys), nil
+	}
+	if len(node.children) != len(node.keys)+1 {
+		return 0, fmt.Errorf("node of %d keys has %d children", len(node.keys), len(node.children))
+	}
+	size := len(node.keys)
+	for i, child := range node.children {
+		var childLower, childUpper *KeyInt
+		if i > 0 {
+			childLower = &node.keys[i-1]
+		}
+		if i < len(node.keys) {
+			childUpper = &node.keys[i]
+		}
+		childSize, err := tree.validateNode(child, childLower, childUpper, depth-1)
+		if err != nil {
+			return 0, err
+		}
+		size += childSize
+	}
+	return size, nil
+}
+
+// Check the order of the keys, the number of keys of every node, that the
+// leaves are on the same level and the number of keys of the tree
+func (tree *BTree) Validate() error {
+	if tree.root == nil {
+		return fmt.Errorf("missing root")
+	}
+	size, err := tree.validateNode(tree.root, nil, nil, tree.height())
+	if err != nil {
+		return err
+	}
+	if size != tree.size {
+		return fmt.Errorf("B-tree has %d keys, it counts %d", size, tree.size)
+	}
+	return nil
+}
+
+/**
+ * Vizualisation
+ */
+
+// A node is drawn as its smallest key, noted with its number of keys
+func (tree *BTree) vizNode(node *bTreeNode) *vizNode {
+	viz := &vizNode{key: &node.keys[0], note: fmt.Sprintf("%d keys", len(node.keys))}
+	for _, child := range node.children {
+		viz.children = append(viz.children, tree.vizNode(child))
+	}
+	return viz
+}
+
+func (tree *BTree) vizForest() []*vizNode {
+	if tree.size == 0 {
+		return nil
+	}
+	return []*vizNode{tree.vizNode(tree.root)}
+}
+
+func (tree *BTree) Viz() []byte {
+	return tree.VizFormat(KeyString)
+}
+
+// Return the tree as a DOT graph, nodes are labelled with the given formatter
+func (tree *BTree) VizFormat(format KeyFormatter) []byte {
+	return vizDot(tree.vizForest(), vizOptions{format: format})
+}
+
+// Draw the tree in the terminal
+func (tree *BTree) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, tree.vizForest(), opts)
+}
@@ -0,0 +1,217 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ lib.OrderedSet = lib.NewBTree(2)
+
+func TestBTreeRandomized(t *testing.T) {
+	for _, degree := range []int{2, 3, 4, 16} {
+		for seed := int64(0); seed < 10; seed++ {
+			r := rand.New(rand.NewSource(seed))
+			tree := lib.NewBTree(degree)
+			model := &heapModel{}
+
+			// enough keys for several levels, few enough for duplicates
+			for step := 0; step < 3000; step++ {
+				key := lib.NewKeyInt(0, uint64(r.Intn(500)))
+				if r.Intn(5) < 3 {
+					tree.Insert(key)
+					model.add(key)
+				} else {
+					i := lowerBound(model.keys, key)
+					found := i < len(model.keys) && model.keys[i].Eq(key)
+					if found {
+						model.keys = append(model.keys[:i], model.keys[i+1:]...)
+					}
+					assert.Equal(t, found, tree.Delete(key))
+				}
+				if step%100 == 0 || step == 2999 {
+					msg := fmt.Sprintf("degree %d seed %d step %d", degree, seed, step)
+					if !assert.NoError(t, tree.Validate(), msg) {
+						return
+					}
+					assert.Equal(t, len(model.keys), tree.Len(), msg)
+				}
+			}
+			assertEqualKeys(t, model.keys, setKeys(tree), "ascend")
+
+			lower, upper := lib.NewKeyInt(0, 100), lib.NewKeyInt(0, 250)
+			ranged := make([]*lib.KeyInt, 0)
+			tree.Range(lower, upper, func(key *lib.KeyInt) bool {
+				ranged = append(ranged, key)
+				return true
+			})
+			expected := model.keys[lowerBound(model.keys, lower):lowerBound(model.keys, upper)]
+			assertEqualKeys(t, expected, ranged, "range")
+		}
+	}
+}
+
+func TestBTreeFile(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	tree := lib.NewBTree(8)
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+	assert.NoError(t, tree.Validate())
+	assert.Equal(t, len(keys), tree.Len())
+	assertEqualKeys(t, sortedKeys(keys), setKeys(tree), "ascend")
+	// at least 7 keys and 8 children per node below the root
+	assert.LessOrEqual(t, tree.MaxLevel(), 5)
+
+	for _, key := range keys {
+		assert.True(t, key.Eq(tree.Get(key)))
+	}
+	for _, key := range getKeysFromFile(t, keysDirName+"jeu_2_nb_cles_1000.txt") {
+		assert.Nil(t, tree.Get(key))
+		assert.False(t, tree.Delete(key))
+	}
+
+	r := rand.New(rand.NewSource(1))
+	r.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	for i, key := range keys {
+		assert.True(t, tree.Delete(key))
+		if i%1000 == 0 {
+			assert.NoError(t, tree.Validate())
+		}
+	}
+	assert.Equal(t, 0, tree.Len())
+	assert.Equal(t, 0, tree.MaxLevel())
+	assert.NoError(t, tree.Validate())
+}
+
+func TestBTreeKeysInline(t *testing.T) {
+	tree := lib.NewBTree(2)
+	key := lib.NewKeyInt(0, 10)
+	tree.Insert(key)
+
+	// the tree holds a copy of the key
+	*key = *lib.NewKeyInt(0, 20)
+	assert.Nil(t, tree.Get(key))
+	assert.True(t, lib.NewKeyInt(0, 10).Eq(tree.Get(lib.NewKeyInt(0, 10))))
+
+	assert.Panics(t, func() { lib.NewBTree(1) })
+}
+
+func TestBTreeKeysAliasing(t *testing.T) {
+	tree := lib.NewBTree(2)
+	for i := 0; i < 200; i++ {
+		tree.Insert(lib.NewKeyInt(0, uint64(i)))
+	}
+
+	// the keys returned by Get and Ascend are copies, unchanged by the
+	// shifts of the nodes
+	kept := tree.Get(lib.NewKeyInt(0, 2))
+	yielded := make([]*lib.KeyInt, 0, tree.Len())
+	tree.Ascend(func(key *lib.KeyInt) bool {
+		yielded = append(yielded, key)
+		return true
+	})
+	for i := 0; i < 200; i += 3 {
+		assert.True(t, tree.Delete(tree.Get(lib.NewKeyInt(0, uint64(i)))))
+		assert.Nil(t, tree.Get(lib.NewKeyInt(0, uint64(i))))
+		assert.NoError(t, tree.Validate())
+	}
+	for i := 0; i < 200; i++ {
+		assert.Equal(t, i%3 != 0, tree.Get(lib.NewKeyInt(0, uint64(i))) != nil)
+		assert.True(t, lib.NewKeyInt(0, uint64(i)).Eq(yielded[i]))
+	}
+	assert.True(t, lib.NewKeyInt(0, 2).Eq(kept))
+
+	for i := 1; i < 200; i += 3 {
+		tree.Insert(tree.Get(lib.NewKeyInt(0, uint64(i))))
+		assert.NoError(t, tree.Validate())
+	}
+	count := 0
+	tree.Ascend(func(key *lib.KeyInt) bool {
+		if key.Eq(lib.NewKeyInt(0, 1)) {
+			count++
+		}
+		return true
+	})
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 133+67, tree.Len())
+}
+
+/**
+ * Benchmarks
+ */
+
+// Return the bytes of heap taken by the set built by build, measured
+// between two garbage collections
+func setFootprint(build func() lib.OrderedSet) (lib.OrderedSet, uint64) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	set := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return set, after.HeapAlloc - before.HeapAlloc
+}
+
+var bTreeBenchSets = []struct {
+	name   string
+	newSet func() lib.OrderedSet
+}{
+	{"searchTree", func() lib.OrderedSet { return lib.NewSearchTree() }},
+	{"treap", func() lib.OrderedSet { return lib.NewTreap() }},
+	{"bTree_4", func() lib.OrderedSet { return lib.NewBTree(4) }},
+	{"bTree_16", func() lib.OrderedSet { return lib.NewBTree(16) }},
+	{"bTree_64", func() lib.OrderedSet { return lib.NewBTree(64) }},
+}
+
+// Lookups of every key of a set of 1M keys, with the heap taken by the set
+// per key; the search trees point to the keys of the caller, they take 16
+// more bytes per key than reported
+func BenchmarkBTreeGet(b *testing.B) {
+	keys := lib.GenKeys(1000000, lib.KeyUniform, 1)
+	lookups := make([]*lib.KeyInt, len(keys))
+	copy(lookups, keys)
+	rand.New(rand.NewSource(2)).Shuffle(len(lookups), func(i, j int) {
+		lookups[i], lookups[j] = lookups[j], lookups[i]
+	})
+
+	for _, bench := range bTreeBenchSets {
+		var set lib.OrderedSet
+		var footprint uint64
+		b.Run(bench.name+"/extra_jeu_nb_cles_1000000", func(b *testing.B) {
+			if set == nil {
+				set, footprint = setFootprint(func() lib.OrderedSet {
+					set := bench.newSet()
+					for _, key := range keys {
+						set.Insert(key)
+					}
+					return set
+				})
+			}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				set.Get(lookups[n%len(lookups)])
+			}
+			b.ReportMetric(float64(footprint)/float64(len(keys)), "bytes/key")
+			b.ReportMetric(float64(set.MaxLevel()), "maxlevel")
+		})
+	}
+}
+
+func BenchmarkBTreeInsert(b *testing.B) {
+	keys := lib.GenKeys(1000000, lib.KeyUniform, 1)
+	for _, bench := range bTreeBenchSets {
+		b.Run(bench.name+"/extra_jeu_nb_cles_1000000", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				set := bench.newSet()
+				for _, key := range keys {
+					set.Insert(key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !debug
+
+package lib
+
+// Check the preconditions of the structures, go build -tags debug enables it
+const debug = false
@@ -0,0 +1,354 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// Memory budget of ExternalSort when none is given, in bytes
+	DefaultSortMemory = 64 << 20
+	// Number of runs merged at once when none is given, more runs are first
+	// merged into bigger ones
+	DefaultMaxOpenRuns = 128
+	// Memory used by a key of a chunk: the KeyInt itself, its pointer in
+	// the chunk and its pointer in the heap array
+	sortKeyMemory = 16 + 8 + 8
+)
+
+type ExternalSortOptions struct {
+	// Bytes of keys held in memory at once, DefaultSortMemory when 0
+	Memory int64
+	// Directory of the runs, the default temporary directory when empty
+	TempDir string
+	// Greatest number of runs merged at once, DefaultMaxOpenRuns when 0
+	MaxOpenRuns int
+}
+
+// Iterate over a slice of keys
+type sliceKeyIterator struct {
+	keys []*KeyInt
+}
+
+func (it *sliceKeyIterator) Next() (*KeyInt, error) {
+	if len(it.keys) == 0 {
+		return nil, io.EOF
+	}
+	key := it.keys[0]
+	it.keys = it.keys[1:]
+	return key, nil
+}
+
+// Return an iterator over the keys of the slice, in order
+func IterateKeys(keys []*KeyInt) KeyIterator {
+	return &sliceKeyIterator{keys}
+}
+
+// Empty the heap, smallest key first
+type heapKeyIterator struct {
+	heap MinHeap
+}
+
+func (it *heapKeyIterator) Next() (*KeyInt, error) {
+	if key := it.heap.SupprMin(); key != nil {
+		return key, nil
+	}
+	return nil, io.EOF
+}
+
+/**
+ * Runs
+ */
+
+// Merge sorted runs, the files stay open until close
+type runMerger struct {
+	keys  KeyIterator
+	files []*os.File
+	count uint64
+}
+
+func newRunMerger(paths []string) (*runMerger, error) {
+	merger := &runMerger{}
+	readers := make([]KeyIterator, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			merger.close()
+			return nil, err
+		}
+		merger.files = append(merger.files, f)
+
+		reader, err := NewBinaryKeyReader(f)
+		if err != nil {
+			merger.close()
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		readers = append(readers, reader)
+		merger.count += reader.Count()
+	}
+	merger.keys = MergeSorted(readers...)
+	return merger, nil
+}
+
+func (merger *runMerger) Next() (*KeyInt, error) {
+	return merger.keys.Next()
+}
+
+func (merger *runMerger) close() {
+	for _, f := range merger.files {
+		f.Close()
+	}
+}
+
+// Write the keys to a new run file of the directory
+func writeRun(dir string, count uint64, keys KeyIterator) (string, error) {
+	f, err := os.CreateTemp(dir, "run-*"+BinaryKeyExt)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	writer, err := NewBinaryKeyWriter(f, count, BinaryKeyOptions{})
+	if err != nil {
+		return "", err
+	}
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := writer.Write(key); err != nil {
+			return "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// Merge the runs into a single one, the merged runs are removed
+func mergeRuns(dir string, paths []string) (string, error) {
+	merger, err := newRunMerger(paths)
+	if err != nil {
+		return "", err
+	}
+	path, err := writeRun(dir, merger.count, merger)
+	merger.close()
+	if err != nil {
+		return "", err
+	}
+	for _, merged := range paths {
+		os.Remove(merged)
+	}
+	return path, nil
+}
+
+/**
+ * External sort
+ */
+
+// SortedRuns yields the keys sorted by ExternalSort, Close removes the
+// temporary runs
+type SortedRuns struct {
+	keys   KeyIterator
+	merger *runMerger
+	dir    string
+	count  uint64
+	runs   int
+}
+
+func (sorted *SortedRuns) Next() (*KeyInt, error) {
+	return sorted.keys.Next()
+}
+
+// Number of keys sorted
+func (sorted *SortedRuns) Count() uint64 {
+	return sorted.count
+}
+
+// Number of runs spilled to disk, 0 when every key fit in memory
+func (sorted *SortedRuns) Runs() int {
+	return sorted.runs
+}
+
+func (sorted *SortedRuns) Close() error {
+	if sorted.merger != nil {
+		sorted.merger.close()
+	}
+	if sorted.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(sorted.dir)
+}
+
+/*
+ExternalSort
+
+Sort more keys than the memory can hold;
+
+ 1. Read chunks of keys fitting in the memory budget, sort each one with a
+    MinHeapArray and spill it to a temporary run file.
+ 2. While there are more runs than MaxOpenRuns, merge them into bigger runs.
+ 3. Merge the remaining runs with MergeSorted.
+
+When every key fits in memory nothing is written to disk. The returned
+SortedRuns must be closed to remove the runs.
+*/
+func ExternalSort(keys KeyIterator, opts ExternalSortOptions) (*SortedRuns, error) {
+	if opts.Memory == 0 {
+		opts.Memory = DefaultSortMemory
+	}
+	if opts.MaxOpenRuns == 0 {
+		opts.MaxOpenRuns = DefaultMaxOpenRuns
+	}
+	if opts.MaxOpenRuns < 2 {
+		return nil, fmt.Errorf("cannot merge less than 2 runs at once")
+	}
+	chunkSize := int(max(opts.Memory/sortKeyMemory, 1))
+
+	sorted := &SortedRuns{}
+	paths := make([]string, 0)
+	// first key of the next chunk, read to know whether there is one
+	var next *KeyInt
+	for done := false; !done; {
+		chunk := make([]*KeyInt, 0, min(chunkSize, 1<<16))
+		if next != nil {
+			chunk = append(chunk, next)
+		}
+		for next = nil; next == nil && !done; {
+			key, err := keys.Next()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				sorted.Close()
+				return nil, err
+			}
+			// a full chunk is only spilled when more keys follow
+			if len(chunk) == chunkSize {
+				next = key
+			} else {
+				chunk = append(chunk, key)
+			}
+		}
+		sorted.count += uint64(len(chunk))
+
+		heap := NewMinHeapArray()
+		heap.Construction(chunk)
+		if done && len(paths) == 0 {
+			// everything fits in memory
+			sorted.keys = &heapKeyIterator{heap}
+			return sorted, nil
+		}
+
+		if sorted.dir == "" {
+			dir, err := os.MkdirTemp(opts.TempDir, "arithmos-sort-")
+			if err != nil {
+				return nil, err
+			}
+			sorted.dir = dir
+		}
+		path, err := writeRun(sorted.dir, uint64(len(chunk)), &heapKeyIterator{heap})
+		if err != nil {
+			sorted.Close()
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	sorted.runs = len(paths)
+
+	for len(paths) > opts.MaxOpenRuns {
+		path, err := mergeRuns(sorted.dir, paths[:opts.MaxOpenRuns])
+		if err != nil {
+			sorted.Close()
+			return nil, err
+		}
+		paths = append(paths[opts.MaxOpenRuns:], path)
+	}
+
+	merger, err := newRunMerger(paths)
+	if err != nil {
+		sorted.Close()
+		return nil, err
+	}
+	sorted.merger = merger
+	sorted.keys = merger
+	return sorted, nil
+}
+
+// Sort a key file into another one, binary when the input is binary or
+// binary is set; the runs go next to the output file unless opts.TempDir is set
+func ExternalSortFile(inPath string, outPath string, binary bool, opts ExternalSortOptions) error {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	keys, err := NewKeyFileReader(in)
+	if err != nil {
+		return fmt.Errorf("%s: %v", inPath, err)
+	}
+	if _, ok := keys.(*BinaryKeyReader); ok {
+		binary = true
+	}
+	if opts.TempDir == "" {
+		opts.TempDir = filepath.Dir(outPath)
+	}
+
+	sorted, err := ExternalSort(keys, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %v", inPath, err)
+	}
+	defer sorted.Close()
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if binary {
+		writer, err := NewBinaryKeyWriter(out, sorted.Count(), BinaryKeyOptions{})
+		if err != nil {
+			return err
+		}
+		if err := copyKeys(writer.Write, sorted); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+	} else {
+		writer := NewKeyWriter(out)
+		if err := copyKeys(writer.Write, sorted); err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func copyKeys(write func(key *KeyInt) error, keys KeyIterator) error {
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := write(key); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func externalSort(t *testing.T, keys []*lib.KeyInt, opts lib.ExternalSortOptions) ([]*lib.KeyInt, int) {
+	sorted, err := lib.ExternalSort(lib.IterateKeys(keys), opts)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, sorted.Close()) }()
+
+	assert.Equal(t, uint64(len(keys)), sorted.Count())
+	all, err := lib.ReadAllKeys(sorted)
+	assert.NoError(t, err)
+	return all, sorted.Runs()
+}
+
+func TestExternalSort(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	expected := sortedKeys(keys)
+
+	// everything fits in memory
+	tempDir := t.TempDir()
+	all, runs := externalSort(t, keys, lib.ExternalSortOptions{TempDir: tempDir})
+	assert.Equal(t, expected, all)
+	assert.Equal(t, 0, runs)
+
+	// 1000 keys per run
+	all, runs = externalSort(t, keys, lib.ExternalSortOptions{Memory: 32000, TempDir: tempDir})
+	assert.Equal(t, expected, all)
+	assert.Equal(t, 20, runs)
+
+	// merged 3 runs at a time
+	all, runs = externalSort(t, keys,
+		lib.ExternalSortOptions{Memory: 32000, TempDir: tempDir, MaxOpenRuns: 3})
+	assert.Equal(t, expected, all)
+	assert.Equal(t, 20, runs)
+
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	// exactly one run of keys still fits in memory
+	sorted, err := lib.ExternalSort(lib.IterateKeys(keys[:1000]),
+		lib.ExternalSortOptions{Memory: 32000, TempDir: tempDir})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, sorted.Runs())
+	entries, err = os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+	all, err = lib.ReadAllKeys(sorted)
+	assert.NoError(t, err)
+	assert.Equal(t, sortedKeys(keys[:1000]), all)
+	assert.NoError(t, sorted.Close())
+
+	all, runs = externalSort(t, keys[:1001], lib.ExternalSortOptions{Memory: 32000, TempDir: tempDir})
+	assert.Equal(t, sortedKeys(keys[:1001]), all)
+	assert.Equal(t, 2, runs)
+
+	duplicates := lib.GenKeys(5000, lib.KeyDuplicates, 1)
+	all, runs = externalSort(t, duplicates, lib.ExternalSortOptions{Memory: 3200, TempDir: tempDir})
+	assert.Equal(t, 50, runs)
+	assert.Equal(t, sortedKeys(duplicates), all)
+
+	all, runs = externalSort(t, nil, lib.ExternalSortOptions{})
+	assert.Equal(t, 0, len(all))
+	assert.Equal(t, 0, runs)
+
+	_, err = lib.ExternalSort(lib.IterateKeys(keys), lib.ExternalSortOptions{MaxOpenRuns: 1})
+	assert.Error(t, err)
+}
+
+type failingKeyIterator struct {
+	keys lib.KeyIterator
+	left int
+}
+
+func (it *failingKeyIterator) Next() (*lib.KeyInt, error) {
+	if it.left == 0 {
+		return nil, errors.New("disk on fire")
+	}
+	it.left--
+	return it.keys.Next()
+}
+
+func TestExternalSortError(t *testing.T) {
+	tempDir := t.TempDir()
+	keys := lib.GenKeys(1000, lib.KeyUniform, 1)
+	_, err := lib.ExternalSort(&failingKeyIterator{lib.IterateKeys(keys), 500},
+		lib.ExternalSortOptions{Memory: 3200, TempDir: tempDir})
+	assert.ErrorContains(t, err, "disk on fire")
+
+	entries, err := os.ReadDir(tempDir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+}
+
+func TestExternalSortFile(t *testing.T) {
+	dir := t.TempDir()
+	keys := lib.GenKeys(3000, lib.KeyUniform, 1)
+	opts := lib.ExternalSortOptions{Memory: 3200}
+
+	in := filepath.Join(dir, "keys.txt")
+	f, _ := os.Create(in)
+	lib.WriteKeys(f, keys)
+	f.Close()
+
+	for _, binary := range []bool{false, true} {
+		out := filepath.Join(dir, "sorted")
+		assert.NoError(t, lib.ExternalSortFile(in, out, binary, opts))
+
+		f, err := os.Open(out)
+		assert.NoError(t, err)
+		reader, err := lib.NewKeyFileReader(f)
+		assert.NoError(t, err)
+		_, isBinary := reader.(*lib.BinaryKeyReader)
+		assert.Equal(t, binary, isBinary)
+
+		all, err := lib.ReadAllKeys(reader)
+		f.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, sortedKeys(keys), all)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	assert.Equal(t, 2, len(entries))
+
+	err := lib.ExternalSortFile(filepath.Join(dir, "missing"), filepath.Join(dir, "out"), false, opts)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
@@ -0,0 +1,287 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+heapArray is the array layout shared by MinHeapArray, MaxHeapArray and
+MinMaxHeapArray: the children of the key at index i are at 2i+1 and 2i+2.
+It sifts keys as a binary heap whose root is the minimum, or the maximum
+when max is set.
+*/
+type heapArray struct {
+	array  []*KeyInt
+	tracer Tracer
+	max    bool
+}
+
+/*
+Checks if heap is empty.
+*/
+func (heap *heapArray) isEmpty() bool {
+	return len(heap.array) == 0
+}
+
+/*
+Checks if an index exists.
+*/
+func (heap *heapArray) isExists(i int) bool {
+	return i < len(heap.array)
+}
+
+/*
+Checks if an index has left child.
+*/
+func (heap *heapArray) hasLeftChild(i int) bool {
+	return heap.isExists(heap.left(i))
+}
+
+/*
+Returns parent from an index.
+*/
+func (heap *heapArray) parent(i int) int {
+	return (i - 1) / 2
+}
+
+/*
+Returns left child from an index.
+*/
+func (heap *heapArray) left(i int) int {
+	return (2 * i) + 1
+}
+
+/*
+Returns right child from an index.
+*/
+func (heap *heapArray) right(i int) int {
+	return (2 * i) + 2
+}
+
+/*
+SetTracer registers a function called on every compare, swap and cut, nil disables it.
+*/
+func (heap *heapArray) SetTracer(tracer Tracer) {
+	heap.tracer = tracer
+}
+
+/*
+Reports an event to the tracer, if any.
+*/
+func (heap *heapArray) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt) {
+	if heap.tracer != nil {
+		heap.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: heap})
+	}
+}
+
+/*
+Len returns the number of keys of the heap.
+*/
+func (heap *heapArray) Len() int {
+	return len(heap.array)
+}
+
+/*
+Checks if the key a must be above the key b on a level of the given kind:
+inferior on a min level, superior on a max level.
+*/
+func above(a *KeyInt, b *KeyInt, minLevel bool) bool {
+	if minLevel {
+		return a.Inf(b)
+	}
+	return b.Inf(a)
+}
+
+/*
+Same as above for the keys at index i and j, reporting the comparison.
+*/
+func (heap *heapArray) before(i int, j int, minLevel bool) bool {
+	heap.trace(TraceCompare, heap.array[i], heap.array[j])
+	return above(heap.array[i], heap.array[j], minLevel)
+}
+
+/*
+Swaps the keys at index i and j, reported in their order before the swap.
+*/
+func (heap *heapArray) swap(i int, j int) {
+	heap.array[i], heap.array[j] = heap.array[j], heap.array[i]
+	heap.trace(TraceSwap, heap.array[j], heap.array[i])
+}
+
+/*
+Returns the root, the minimum or the maximum, nil when the heap is empty.
+*/
+func (heap *heapArray) top() *KeyInt {
+	if heap.isEmpty() {
+		return nil
+	}
+	return heap.array[0]
+}
+
+/*
+Removes the root, swapped with the last key which is then sifted down.
+*/
+func (heap *heapArray) removeTop() *KeyInt {
+	if heap.isEmpty() {
+		return nil
+	}
+
+	last := len(heap.array) - 1
+	if last > 0 {
+		heap.swap(last, 0)
+	}
+
+	topKey := heap.array[last]
+	heap.array = heap.array[:last]
+	heap.trace(TraceCut, topKey, nil)
+
+	heap.siftDown(0)
+	return topKey
+}
+
+/*
+Replaces the root by the key in a single sift, cheaper than removing it
+followed by Ajout; the heap must not be empty.
+*/
+func (heap *heapArray) replaceTop(key *KeyInt) *KeyInt {
+	topKey := heap.array[0]
+	heap.array[0] = key
+	heap.trace(TraceCut, topKey, nil)
+	heap.siftDown(0)
+	return topKey
+}
+
+/*
+Moves the key at the index down, swapping it with its smaller child, or its
+greater one in a max heap, while they are in the wrong order.
+*/
+func (heap *heapArray) siftDown(keyIndex int) {
+	for heap.hasLeftChild(keyIndex) {
+		child := heap.left(keyIndex)
+
+		// Check if there is a right child and if it goes before the left child
+		if right := heap.right(keyIndex); heap.isExists(right) && heap.before(right, child, !heap.max) {
+			child = right
+		}
+
+		// Compare the chosen child with the parent
+		if !heap.before(child, keyIndex, !heap.max) {
+			return
+		}
+		heap.swap(child, keyIndex)
+		keyIndex = child
+	}
+}
+
+/*
+Moves the key at the index up while it goes before its parent.
+*/
+func (heap *heapArray) siftUp(keyIndex int) {
+	for keyIndex > 0 {
+		parentIndex := heap.parent(keyIndex)
+		if !heap.before(keyIndex, parentIndex, !heap.max) {
+			return
+		}
+		heap.swap(keyIndex, parentIndex)
+		keyIndex = parentIndex
+	}
+}
+
+/*
+Ajout
+
+Add a new element to the end of an array;
+
+ 1. Sift up the new element, while heap property is broken.
+ 2. Sifting is done as following: compare node's value with parent's value.
+    If they are in wrong order, swap them.
+*/
+func (heap *heapArray) Ajout(key *KeyInt) {
+	heap.array = append(heap.array, key)
+	heap.siftUp(len(heap.array) - 1)
+}
+
+func (heap *heapArray) AjoutIteratif(keys []*KeyInt) {
+	for _, key := range keys {
+		heap.Ajout(key)
+	}
+}
+
+func (heap *heapArray) Construction(keys []*KeyInt) {
+	// Add every key to array
+	heap.array = append(heap.array, keys...)
+
+	// Sift down every tree
+	for i := len(heap.array)/2 - 1; i >= 0; i-- {
+		heap.siftDown(i)
+	}
+}
+
+/*
+Validate checks that every key is set and does not go before its parent,
+inferior in a min heap and superior in a max heap.
+*/
+func (heap *heapArray) Validate() error {
+	order := "inferior"
+	if heap.max {
+		order = "superior"
+	}
+	for i, key := range heap.array {
+		if key == nil {
+			return fmt.Errorf("nil key at index %d", i)
+		}
+		if i == 0 {
+			continue
+		}
+		if parentKey := heap.array[heap.parent(i)]; above(key, parentKey, !heap.max) {
+			return fmt.Errorf("heap order broken at index %d: %v is %s to its parent %v",
+				i, key, order, parentKey)
+		}
+	}
+	return nil
+}
+
+func (heap *heapArray) String() string {
+	text := "["
+	for i, key := range heap.array {
+		if i > 0 {
+			text += ", "
+		}
+		text += key.String()
+	}
+	return text + "]"
+}
+
+func (heap *heapArray) vizNode(i int) *vizNode {
+	if !heap.isExists(i) {
+		return nil
+	}
+	return newVizBinaryNode(heap.array[i],
+		heap.vizNode(heap.left(i)), heap.vizNode(heap.right(i)))
+}
+
+func (heap *heapArray) vizForest() []*vizNode {
+	if heap.isEmpty() {
+		return nil
+	}
+	return []*vizNode{heap.vizNode(0)}
+}
+
+func (heap *heapArray) Viz() []byte {
+	return heap.VizFormat(KeyString)
+}
+
+/*
+VizFormat returns the heap as a DOT graph, labelling nodes with the given formatter.
+*/
+func (heap *heapArray) VizFormat(format KeyFormatter) []byte {
+	return vizDot(heap.vizForest(), vizOptions{format: format})
+}
+
+/*
+Pretty draws the heap as a tree in the terminal.
+*/
+func (heap *heapArray) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, heap.vizForest(), opts)
+}
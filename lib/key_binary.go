@@ -0,0 +1,223 @@
+package lib
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+/**
+ * Binary key files
+ *
+ * A 16 bytes header followed by one 16 bytes record per key, then the
+ * optional checksum:
+ *
+ *   offset  size  field
+ *   0       4     magic "AKEY"
+ *   4       1     version, BinaryKeyVersion
+ *   5       1     byte order of the numbers, 'L' or 'B'
+ *   6       1     flags, binaryKeyChecksum when the checksum is present
+ *   7       1     reserved, 0
+ *   8       8     number of keys
+ *   16      16*n  keys, high 64b then low 64b
+ *   16+16*n 4     CRC-32 (IEEE) of the records
+ */
+
+const (
+	binaryKeyMagic       = "AKEY"
+	BinaryKeyVersion     = 1
+	binaryKeyHeaderSize  = 16
+	binaryKeyRecordSize  = 16
+	binaryKeyChecksum    = 1 << 0
+	binaryKeyLittleOrder = 'L'
+	binaryKeyBigOrder    = 'B'
+)
+
+// Extension of the binary key files, the text ones end with .txt
+const BinaryKeyExt = ".bin"
+
+type BinaryKeyOptions struct {
+	// binary.LittleEndian or binary.BigEndian, binary.LittleEndian when nil
+	ByteOrder binary.ByteOrder
+	// Append a CRC-32 of the records, checked by the reader
+	Checksum bool
+}
+
+// BinaryKeyWriter streams keys to a binary key file, the number of keys is
+// part of the header so it is given up front
+type BinaryKeyWriter struct {
+	out     *bufio.Writer
+	order   binary.ByteOrder
+	count   uint64
+	written uint64
+	crc     hash.Hash32
+	record  [binaryKeyRecordSize]byte
+}
+
+// Write the header of a file of count keys
+func NewBinaryKeyWriter(w io.Writer, count uint64, opts BinaryKeyOptions) (*BinaryKeyWriter, error) {
+	writer := &BinaryKeyWriter{out: bufio.NewWriter(w), order: opts.ByteOrder, count: count}
+	if writer.order == nil {
+		writer.order = binary.LittleEndian
+	}
+
+	header := make([]byte, binaryKeyHeaderSize)
+	copy(header, binaryKeyMagic)
+	header[4] = BinaryKeyVersion
+	switch writer.order {
+	case binary.LittleEndian:
+		header[5] = binaryKeyLittleOrder
+	case binary.BigEndian:
+		header[5] = binaryKeyBigOrder
+	default:
+		return nil, fmt.Errorf("unsupported byte order %v", writer.order)
+	}
+	if opts.Checksum {
+		header[6] |= binaryKeyChecksum
+		writer.crc = crc32.NewIEEE()
+	}
+	writer.order.PutUint64(header[8:], count)
+
+	_, err := writer.out.Write(header)
+	return writer, err
+}
+
+func (writer *BinaryKeyWriter) Write(key *KeyInt) error {
+	if writer.written == writer.count {
+		return fmt.Errorf("more than the %d keys of the header", writer.count)
+	}
+	writer.order.PutUint64(writer.record[0:], key.u1)
+	writer.order.PutUint64(writer.record[8:], key.u2)
+	if writer.crc != nil {
+		writer.crc.Write(writer.record[:])
+	}
+	writer.written++
+	_, err := writer.out.Write(writer.record[:])
+	return err
+}
+
+// Write the checksum, if any, and flush; the underlying writer is not closed
+func (writer *BinaryKeyWriter) Close() error {
+	if writer.written != writer.count {
+		return fmt.Errorf("%d keys written, the header announces %d", writer.written, writer.count)
+	}
+	if writer.crc != nil {
+		sum := make([]byte, 4)
+		writer.order.PutUint32(sum, writer.crc.Sum32())
+		if _, err := writer.out.Write(sum); err != nil {
+			return err
+		}
+	}
+	return writer.out.Flush()
+}
+
+// Write every key as a binary key file
+func WriteBinaryKeys(w io.Writer, keys []*KeyInt, opts BinaryKeyOptions) error {
+	writer, err := NewBinaryKeyWriter(w, uint64(len(keys)), opts)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := writer.Write(key); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// BinaryKeyReader streams the keys of a binary key file
+type BinaryKeyReader struct {
+	in     *bufio.Reader
+	order  binary.ByteOrder
+	count  uint64
+	read   uint64
+	crc    hash.Hash32
+	record [binaryKeyRecordSize]byte
+}
+
+// Read and check the header of a binary key file
+func NewBinaryKeyReader(r io.Reader) (*BinaryKeyReader, error) {
+	reader := &BinaryKeyReader{in: bufio.NewReader(r)}
+
+	header := make([]byte, binaryKeyHeaderSize)
+	if _, err := io.ReadFull(reader.in, header); err != nil {
+		return nil, fmt.Errorf("header: %v", noEOF(err))
+	}
+	if string(header[:4]) != binaryKeyMagic {
+		return nil, fmt.Errorf("not a binary key file, magic %q", header[:4])
+	}
+	if header[4] != BinaryKeyVersion {
+		return nil, fmt.Errorf("unsupported version %d", header[4])
+	}
+	switch header[5] {
+	case binaryKeyLittleOrder:
+		reader.order = binary.LittleEndian
+	case binaryKeyBigOrder:
+		reader.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("unknown byte order %q", header[5])
+	}
+	if header[6]&binaryKeyChecksum != 0 {
+		reader.crc = crc32.NewIEEE()
+	}
+	reader.count = reader.order.Uint64(header[8:])
+
+	return reader, nil
+}
+
+// Number of keys of the file, as announced by the header
+func (reader *BinaryKeyReader) Count() uint64 {
+	return reader.count
+}
+
+// Return the next key, or io.EOF once every key is read and the checksum,
+// if any, is verified
+func (reader *BinaryKeyReader) Next() (*KeyInt, error) {
+	if reader.read == reader.count {
+		return nil, reader.checkSum()
+	}
+	if _, err := io.ReadFull(reader.in, reader.record[:]); err != nil {
+		return nil, fmt.Errorf("key %d of %d: %v", reader.read+1, reader.count, noEOF(err))
+	}
+	if reader.crc != nil {
+		reader.crc.Write(reader.record[:])
+	}
+	reader.read++
+	return &KeyInt{reader.order.Uint64(reader.record[0:]), reader.order.Uint64(reader.record[8:])}, nil
+}
+
+func (reader *BinaryKeyReader) checkSum() error {
+	if reader.crc == nil {
+		return io.EOF
+	}
+	sum := make([]byte, 4)
+	if _, err := io.ReadFull(reader.in, sum); err != nil {
+		return fmt.Errorf("checksum: %v", noEOF(err))
+	}
+	if expected, actual := reader.order.Uint32(sum), reader.crc.Sum32(); expected != actual {
+		return fmt.Errorf("checksum mismatch, file has %08x, keys give %08x", expected, actual)
+	}
+	// only check it once
+	reader.crc = nil
+	return io.EOF
+}
+
+// Read every key of a binary key file
+func ReadBinaryKeys(r io.Reader) ([]*KeyInt, error) {
+	reader, err := NewBinaryKeyReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return ReadAllKeys(reader)
+}
+
+// A file cut short is corrupted, not merely finished
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryKeys(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+
+	for _, opts := range []lib.BinaryKeyOptions{
+		{},
+		{ByteOrder: binary.BigEndian},
+		{Checksum: true},
+		{ByteOrder: binary.BigEndian, Checksum: true},
+	} {
+		buf := &bytes.Buffer{}
+		assert.NoError(t, lib.WriteBinaryKeys(buf, keys, opts))
+		size := 16 + 16*len(keys)
+		if opts.Checksum {
+			size += 4
+		}
+		assert.Equal(t, size, buf.Len())
+		assert.Equal(t, "AKEY", buf.String()[:4])
+
+		read, err := lib.ReadBinaryKeys(bytes.NewReader(buf.Bytes()))
+		assert.NoError(t, err)
+		assert.Equal(t, keys, read)
+
+		read, err = lib.ReadKeys(bytes.NewReader(buf.Bytes()))
+		assert.NoError(t, err)
+		assert.Equal(t, keys, read)
+	}
+
+	// the byte order is the one of the header
+	little, big := &bytes.Buffer{}, &bytes.Buffer{}
+	lib.WriteBinaryKeys(little, keys[:1], lib.BinaryKeyOptions{})
+	lib.WriteBinaryKeys(big, keys[:1], lib.BinaryKeyOptions{ByteOrder: binary.BigEndian})
+	assert.Equal(t, byte('L'), little.Bytes()[5])
+	assert.Equal(t, byte('B'), big.Bytes()[5])
+	assert.Equal(t, []byte{1, 0, 0, 0, 0, 0, 0, 0}, little.Bytes()[8:16])
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1}, big.Bytes()[8:16])
+
+	// the header cannot describe any other order, even a little endian one
+	other := lib.BinaryKeyOptions{ByteOrder: otherLittleEndian{binary.LittleEndian}}
+	_, err := lib.NewBinaryKeyWriter(&bytes.Buffer{}, 1, other)
+	assert.Error(t, err)
+	assert.Error(t, lib.WriteBinaryKeys(&bytes.Buffer{}, keys[:1], other))
+}
+
+// A byte order which is not one of the binary package
+type otherLittleEndian struct {
+	binary.ByteOrder
+}
+
+func (otherLittleEndian) String() string { return "otherLittleEndian" }
+
+func TestBinaryKeyReaderStream(t *testing.T) {
+	keys := genKeys()
+	buf := &bytes.Buffer{}
+	writer, err := lib.NewBinaryKeyWriter(buf, uint64(len(keys)), lib.BinaryKeyOptions{Checksum: true})
+	assert.NoError(t, err)
+	for _, key := range keys {
+		assert.NoError(t, writer.Write(key))
+	}
+	assert.ErrorContains(t, writer.Write(keys[0]), "more than the 5 keys")
+	assert.NoError(t, writer.Close())
+
+	reader, err := lib.NewBinaryKeyReader(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), reader.Count())
+	for _, key := range keys {
+		read, err := reader.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, key, read)
+	}
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	writer, _ = lib.NewBinaryKeyWriter(&bytes.Buffer{}, 2, lib.BinaryKeyOptions{})
+	writer.Write(keys[0])
+	assert.ErrorContains(t, writer.Close(), "1 keys written, the header announces 2")
+}
+
+func TestBinaryKeysCorrupted(t *testing.T) {
+	keys := genKeys()
+	buf := &bytes.Buffer{}
+	lib.WriteBinaryKeys(buf, keys, lib.BinaryKeyOptions{Checksum: true})
+	data := buf.Bytes()
+
+	readErr := func(data []byte) error {
+		_, err := lib.ReadBinaryKeys(bytes.NewReader(data))
+		return err
+	}
+
+	flipped := bytes.Clone(data)
+	flipped[20] ^= 1
+	assert.ErrorContains(t, readErr(flipped), "checksum mismatch")
+
+	assert.ErrorContains(t, readErr(data[:40]), "key 2 of 5: unexpected EOF")
+	assert.ErrorContains(t, readErr(data[:len(data)-2]), "checksum: unexpected EOF")
+	assert.ErrorContains(t, readErr(data[:10]), "header: unexpected EOF")
+
+	wrong := bytes.Clone(data)
+	copy(wrong, "KEYS")
+	assert.ErrorContains(t, readErr(wrong), "not a binary key file")
+
+	wrong = bytes.Clone(data)
+	wrong[4] = 2
+	assert.ErrorContains(t, readErr(wrong), "unsupported version 2")
+
+	wrong = bytes.Clone(data)
+	wrong[5] = 'X'
+	assert.ErrorContains(t, readErr(wrong), "unknown byte order 'X'")
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkLoadKeys(b *testing.B) {
+	path := keysDirName + "jeu_1_nb_cles_120000.txt"
+	text, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	buf := &bytes.Buffer{}
+	lib.WriteBinaryKeys(buf, getKeysFromFile(b, path), lib.BinaryKeyOptions{Checksum: true})
+	bin := buf.Bytes()
+
+	b.Run("text/jeu_1_nb_cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lib.ReadKeys(bytes.NewReader(text))
+		}
+	})
+	b.Run("binary/jeu_1_nb_cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			lib.ReadKeys(bytes.NewReader(bin))
+		}
+	})
+}
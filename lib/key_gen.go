@@ -0,0 +1,146 @@
+package lib
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// KeyDistribution is the way GenKeys draws its keys
+type KeyDistribution int
+
+const (
+	// Independent keys over the whole 128b range, like the cles_alea files
+	KeyUniform KeyDistribution = iota
+	// Uniform keys in increasing order
+	KeySorted
+	// Uniform keys in decreasing order
+	KeyReverse
+	// Sorted keys where about 1% of the keys are swapped with a close one
+	KeyNearlySorted
+	// Keys drawn from a pool of n/10 distinct keys
+	KeyDuplicates
+	// Keys close to one of a few random centers
+	KeyClustered
+	// Keys drawn from a pool of n distinct keys with a Zipf law, a few
+	// keys are very frequent
+	KeyZipfian
+)
+
+var keyDistributionNames = []string{
+	"uniform", "sorted", "reverse", "nearly-sorted", "duplicates", "clustered", "zipfian",
+}
+
+func (dist KeyDistribution) String() string {
+	if dist < 0 || int(dist) >= len(keyDistributionNames) {
+		return fmt.Sprintf("KeyDistribution(%d)", int(dist))
+	}
+	return keyDistributionNames[dist]
+}
+
+// Return the distribution of the given name, as printed by String
+func ParseKeyDistribution(name string) (KeyDistribution, error) {
+	for i, distName := range keyDistributionNames {
+		if distName == name {
+			return KeyDistribution(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key distribution %q, expected one of %s",
+		name, strings.Join(keyDistributionNames, ", "))
+}
+
+const (
+	// Probability for a key of KeyNearlySorted to be swapped
+	nearlySortedSwaps = 0.01
+	// Greatest distance between two swapped keys of KeyNearlySorted
+	nearlySortedDistance = 10
+	// Number of centers of KeyClustered
+	nbClusters = 16
+	// Width of a cluster of KeyClustered
+	clusterWidth = 1 << 32
+	// Exponent of the Zipf law of KeyZipfian, the greater the fewer keys
+	zipfExponent = 1.1
+)
+
+func randKey(r *rand.Rand) *KeyInt {
+	return &KeyInt{r.Uint64(), r.Uint64()}
+}
+
+// Generate n keys of the given distribution, the same seed always gives the
+// same keys
+func GenKeys(n int, dist KeyDistribution, seed int64) []*KeyInt {
+	r := rand.New(rand.NewSource(seed))
+	keys := make([]*KeyInt, 0, n)
+
+	switch dist {
+	case KeyUniform, KeySorted, KeyReverse, KeyNearlySorted:
+		for i := 0; i < n; i++ {
+			keys = append(keys, randKey(r))
+		}
+
+	case KeyDuplicates, KeyZipfian:
+		nbDistinct := n
+		if dist == KeyDuplicates {
+			nbDistinct = max(n/10, 1)
+		}
+		pool := make([]*KeyInt, 0, nbDistinct)
+		for i := 0; i < nbDistinct; i++ {
+			pool = append(pool, randKey(r))
+		}
+
+		var zipf *rand.Zipf
+		if dist == KeyZipfian && n > 0 {
+			zipf = rand.NewZipf(r, zipfExponent, 1, uint64(nbDistinct-1))
+		}
+		for i := 0; i < n; i++ {
+			// every key has its own pointer, even the duplicated ones
+			var key KeyInt
+			if zipf != nil {
+				key = *pool[zipf.Uint64()]
+			} else {
+				key = *pool[r.Intn(nbDistinct)]
+			}
+			keys = append(keys, &key)
+		}
+
+	case KeyClustered:
+		centers := make([]*KeyInt, 0, nbClusters)
+		for i := 0; i < nbClusters; i++ {
+			centers = append(centers, randKey(r))
+		}
+		for i := 0; i < n; i++ {
+			center := centers[r.Intn(nbClusters)]
+			u2, carry := bits.Add64(center.u2, uint64(r.Int63n(clusterWidth)), 0)
+			keys = append(keys, &KeyInt{center.u1 + carry, u2})
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown key distribution %v", dist))
+	}
+
+	switch dist {
+	case KeySorted:
+		SortKeys(keys)
+	case KeyReverse:
+		SortKeys(keys)
+		slices.Reverse(keys)
+	case KeyNearlySorted:
+		SortKeys(keys)
+		for i := range keys {
+			if r.Float64() < nearlySortedSwaps {
+				j := min(i+1+r.Intn(nearlySortedDistance), n-1)
+				keys[i], keys[j] = keys[j], keys[i]
+			}
+		}
+	}
+
+	return keys
+}
+
+// Return the name of a key file, in the format of the cles_alea files
+func KeyFileName(jeu int, nbKeys int) string {
+	return fmt.Sprintf("jeu_%d_nb_cles_%d.txt", jeu, nbKeys)
+}
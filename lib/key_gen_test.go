@@ -0,0 +1,96 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var keyDistributions = []lib.KeyDistribution{
+	lib.KeyUniform, lib.KeySorted, lib.KeyReverse, lib.KeyNearlySorted,
+	lib.KeyDuplicates, lib.KeyClustered, lib.KeyZipfian,
+}
+
+func countKeys(keys []*lib.KeyInt) map[string]int {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		counts[key.Hex()]++
+	}
+	return counts
+}
+
+// Number of keys smaller than the key before them
+func countDescents(keys []*lib.KeyInt) int {
+	descents := 0
+	for i := 1; i < len(keys); i++ {
+		if keys[i].Inf(keys[i-1]) {
+			descents++
+		}
+	}
+	return descents
+}
+
+func TestGenKeys(t *testing.T) {
+	const n = 10000
+	for _, dist := range keyDistributions {
+		keys := lib.GenKeys(n, dist, 42)
+		assert.Equal(t, n, len(keys), dist.String())
+		assert.Equal(t, keys, lib.GenKeys(n, dist, 42), dist.String())
+		assert.NotEqual(t, keys, lib.GenKeys(n, dist, 43), dist.String())
+		assert.Equal(t, 0, len(lib.GenKeys(0, dist, 42)), dist.String())
+
+		parsed, err := lib.ParseKeyDistribution(dist.String())
+		assert.NoError(t, err)
+		assert.Equal(t, dist, parsed)
+	}
+
+	_, err := lib.ParseKeyDistribution("gaussian")
+	assert.ErrorContains(t, err, "unknown key distribution \"gaussian\"")
+
+	assert.Equal(t, n, len(countKeys(lib.GenKeys(n, lib.KeyUniform, 1))))
+	assert.Equal(t, 0, countDescents(lib.GenKeys(n, lib.KeySorted, 1)))
+	assert.Equal(t, n-1, countDescents(lib.GenKeys(n, lib.KeyReverse, 1)))
+
+	descents := countDescents(lib.GenKeys(n, lib.KeyNearlySorted, 1))
+	assert.Greater(t, descents, 0)
+	assert.Less(t, descents, n/25)
+
+	assert.LessOrEqual(t, len(countKeys(lib.GenKeys(n, lib.KeyDuplicates, 1))), n/10)
+
+	// the high 64b of the keys are the ones of a center or the next value
+	highs := make(map[string]bool)
+	for _, key := range lib.GenKeys(n, lib.KeyClustered, 1) {
+		highs[key.Hex()[:18]] = true
+	}
+	assert.LessOrEqual(t, len(highs), 32)
+
+	counts := countKeys(lib.GenKeys(n, lib.KeyZipfian, 1))
+	mostFrequent := 0
+	for _, count := range counts {
+		if count > mostFrequent {
+			mostFrequent = count
+		}
+	}
+	assert.Greater(t, mostFrequent, n/20)
+	assert.Less(t, len(counts), n/2)
+}
+
+func TestWriteKeys(t *testing.T) {
+	assert.Equal(t, "jeu_3_nb_cles_1000.txt", lib.KeyFileName(3, 1000))
+
+	keys := lib.GenKeys(100, lib.KeyUniform, 1)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, lib.WriteKeys(buf, keys))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, len(keys), len(lines))
+	for i, line := range lines {
+		assert.Equal(t, 34, len(line))
+		key, err := lib.NewKeyIntFromString(line)
+		assert.NoError(t, err)
+		assert.Equal(t, keys[i], key)
+	}
+}
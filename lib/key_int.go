@@ -3,6 +3,7 @@ package lib
 import (
 	"encoding/binary"
 	"fmt"
+	"math/bits"
 	"strconv"
 )
 
@@ -25,7 +26,14 @@ func NewKeyIntFromBytes(bytes [16]byte) *KeyInt {
 
 // Create a key from a given hexadecimal string, the format shall be
 // similar to: 0xdf6943ba6d51464f6b02157933bdd9ad
+//
+// The first 16 digits are the high 64b and the others the low 64b, the
+// cles_alea files have keys with less than 32 digits read that way
 func NewKeyIntFromString(str string) (*KeyInt, error) {
+	if len(str) < 19 || len(str) > 34 || (str[:2] != "0x" && str[:2] != "0X") {
+		return nil, fmt.Errorf("invalid key %q, expected 0x and 17 to 32 hexadecimal digits", str)
+	}
+
 	u1, err := strconv.ParseUint(str[2:18], 16, 64)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing u1: %v", err)
@@ -52,3 +60,22 @@ func (key *KeyInt) Eq(other *KeyInt) bool {
 func (key *KeyInt) String() string {
 	return fmt.Sprintf("%v-%v", key.u1, key.u2)
 }
+
+// Return the hexadecimal representation of the key, the same format as
+// the one parsed by NewKeyIntFromString
+func (key *KeyInt) Hex() string {
+	return fmt.Sprintf("0x%016x%016x", key.u1, key.u2)
+}
+
+// Return the bitwise exclusive or of the two keys
+func (key *KeyInt) Xor(other *KeyInt) *KeyInt {
+	return &KeyInt{key.u1 ^ other.u1, key.u2 ^ other.u2}
+}
+
+// Return the number of leading zero bits of the key, 128 for 0
+func (key *KeyInt) LeadingZeros() int {
+	if key.u1 != 0 {
+		return bits.LeadingZeros64(key.u1)
+	}
+	return 64 + bits.LeadingZeros64(key.u2)
+}
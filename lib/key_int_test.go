@@ -49,6 +49,32 @@ func TestString(t *testing.T) {
 	assert.Equal(t, "0-0", key.String())
 }
 
+func TestHex(t *testing.T) {
+	key := lib.NewKeyInt(102, 10)
+	assert.Equal(t, "0x0000000000000066000000000000000a", key.Hex())
+
+	str := "0xdf6943ba6d51464f6b02157933bdd9ad"
+	key, err := lib.NewKeyIntFromString(str)
+	assert.NoError(t, err)
+	assert.Equal(t, str, key.Hex())
+}
+
+func TestKeyFromInvalidString(t *testing.T) {
+	for _, str := range []string{"", "0x", "0x1234", "df6943ba6d51464f6b02157933bdd9ad",
+		"0xdf6943ba6d51464f6b02157933bdd9ad00"} {
+		_, err := lib.NewKeyIntFromString(str)
+		assert.ErrorContains(t, err, "invalid key", str)
+	}
+
+	_, err := lib.NewKeyIntFromString("0xdf6943ba6d51464g6b02157933bdd9ad")
+	assert.ErrorContains(t, err, "error parsing u1")
+
+	// the high 64b are always the first 16 digits
+	key, err := lib.NewKeyIntFromString("0x0000000000000001a")
+	assert.NoError(t, err)
+	assert.Equal(t, lib.NewKeyInt(1, 10), key)
+}
+
 func TestDataset1Keys1000(t *testing.T) {
 	f, err := os.Open("../data/cles_alea/jeu_1_nb_cles_1000.txt")
 	assert.NoError(t, err)
@@ -79,3 +105,12 @@ func TestDataset1Keys1000(t *testing.T) {
 		last = curr
 	}
 }
+
+func TestXorLeadingZeros(t *testing.T) {
+	key := lib.NewKeyInt(0b1010, 0xff)
+	assert.Equal(t, lib.NewKeyInt(0b0010, 0xf0), key.Xor(lib.NewKeyInt(0b1000, 0x0f)))
+	assert.Equal(t, 128, key.Xor(key).LeadingZeros())
+	assert.Equal(t, 60, key.LeadingZeros())
+	assert.Equal(t, 120, lib.NewKeyInt(0, 0xff).LeadingZeros())
+	assert.Equal(t, 0, lib.NewKeyInt(1<<63, 0).LeadingZeros())
+}
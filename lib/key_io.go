@@ -0,0 +1,174 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeyIterator yields keys one at a time, Next returns io.EOF after the last
+// one; KeyReader and BinaryKeyReader are key iterators
+type KeyIterator interface {
+	Next() (*KeyInt, error)
+}
+
+// Read every key left in the iterator
+func ReadAllKeys(keys KeyIterator) ([]*KeyInt, error) {
+	all := make([]*KeyInt, 0)
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			return all, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, key)
+	}
+}
+
+// Add every key of the iterator to the heap without loading them all first,
+// return the number of keys added, even on error
+func AjoutFrom(heap MinHeap, keys KeyIterator) (int, error) {
+	added := 0
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			return added, nil
+		}
+		if err != nil {
+			return added, err
+		}
+		heap.Ajout(key)
+		added++
+	}
+}
+
+/**
+ * Text key files
+ */
+
+// KeyParseError is the error of a malformed line of a text key file
+type KeyParseError struct {
+	Line int
+	Err  error
+}
+
+func (err *KeyParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", err.Line, err.Err)
+}
+
+func (err *KeyParseError) Unwrap() error {
+	return err.Err
+}
+
+// KeyReader reads the keys of a text key file, one hexadecimal key per line
+// like the cles_alea files; the fields can be changed before the first Next
+type KeyReader struct {
+	// Skip the lines with only spaces instead of failing on them
+	SkipBlank bool
+	// Lines starting with Comment are skipped, none when empty
+	Comment string
+
+	s    *bufio.Scanner
+	line int
+}
+
+// Return a reader skipping blank lines and the lines starting with #
+func NewKeyReader(r io.Reader) *KeyReader {
+	return &KeyReader{SkipBlank: true, Comment: "#", s: bufio.NewScanner(r)}
+}
+
+// Number of the last line read, starting at 1
+func (reader *KeyReader) Line() int {
+	return reader.line
+}
+
+// Return the next key, io.EOF at the end of the input or a *KeyParseError
+// on a malformed line
+func (reader *KeyReader) Next() (*KeyInt, error) {
+	for reader.s.Scan() {
+		reader.line++
+		text := strings.TrimSpace(reader.s.Text())
+		if text == "" && reader.SkipBlank {
+			continue
+		}
+		if reader.Comment != "" && strings.HasPrefix(text, reader.Comment) {
+			continue
+		}
+		key, err := NewKeyIntFromString(text)
+		if err != nil {
+			return nil, &KeyParseError{Line: reader.line, Err: err}
+		}
+		return key, nil
+	}
+	if err := reader.s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// KeyWriter writes keys in the format read by KeyReader
+type KeyWriter struct {
+	out *bufio.Writer
+}
+
+func NewKeyWriter(w io.Writer) *KeyWriter {
+	return &KeyWriter{out: bufio.NewWriter(w)}
+}
+
+func (writer *KeyWriter) Write(key *KeyInt) error {
+	writer.out.WriteString(key.Hex())
+	return writer.out.WriteByte('\n')
+}
+
+// Write any buffered key to the underlying writer
+func (writer *KeyWriter) Flush() error {
+	return writer.out.Flush()
+}
+
+// Read every key of a text key file
+func ReadTextKeys(r io.Reader) ([]*KeyInt, error) {
+	return ReadAllKeys(NewKeyReader(r))
+}
+
+// Write the keys in the format of the cles_alea files
+func WriteKeys(w io.Writer, keys []*KeyInt) error {
+	writer := NewKeyWriter(w)
+	for _, key := range keys {
+		if err := writer.Write(key); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+/**
+ * Any key file
+ */
+
+// Return an iterator over a key file, binary or text depending on its
+// first bytes
+func NewKeyFileReader(r io.Reader) (KeyIterator, error) {
+	in := bufio.NewReader(r)
+	magic, err := in.Peek(len(binaryKeyMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if bytes.Equal(magic, []byte(binaryKeyMagic)) {
+		return NewBinaryKeyReader(in)
+	}
+	return NewKeyReader(in), nil
+}
+
+// Read every key of a key file, binary or text
+func ReadKeys(r io.Reader) ([]*KeyInt, error) {
+	keys, err := NewKeyFileReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return ReadAllKeys(keys)
+}
@@ -0,0 +1,107 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyReader(t *testing.T) {
+	text := "# generated keys\n" +
+		"0x00000000000000000000000000000010\n" +
+		"\n" +
+		"  0x0000000000000001000000000000000a  \r\n" +
+		"0x0000000000000000000000000000zz10\n" +
+		"0x00000000000000000000000000000030"
+	reader := lib.NewKeyReader(strings.NewReader(text))
+
+	key, err := reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, lib.NewKeyInt(0, 16), key)
+	assert.Equal(t, 2, reader.Line())
+
+	key, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, lib.NewKeyInt(1, 10), key)
+	assert.Equal(t, 4, reader.Line())
+
+	_, err = reader.Next()
+	var parseErr *lib.KeyParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 5, parseErr.Line)
+	assert.ErrorContains(t, err, "line 5: error parsing u2")
+
+	// the reader goes on after a malformed line
+	key, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, lib.NewKeyInt(0, 48), key)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	reader = lib.NewKeyReader(strings.NewReader(text))
+	reader.SkipBlank = false
+	reader.Comment = ""
+	_, err = reader.Next()
+	assert.ErrorContains(t, err, "line 1: invalid key \"# generated keys\"")
+	reader.Next()
+	_, err = reader.Next()
+	assert.ErrorContains(t, err, "line 3: invalid key \"\"")
+
+	keys, err := lib.ReadTextKeys(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys))
+}
+
+func TestKeyWriter(t *testing.T) {
+	keys := lib.GenKeys(100, lib.KeyUniform, 1)
+	buf := &bytes.Buffer{}
+	writer := lib.NewKeyWriter(buf)
+	for _, key := range keys {
+		assert.NoError(t, writer.Write(key))
+	}
+	assert.NoError(t, writer.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, len(keys), len(lines))
+	assert.Equal(t, keys[0].Hex(), lines[0])
+
+	read, err := lib.ReadTextKeys(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, keys, read)
+
+	buf.Reset()
+	assert.NoError(t, lib.WriteKeys(buf, keys))
+	read, err = lib.ReadKeys(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, keys, read)
+}
+
+func TestKeyFileReader(t *testing.T) {
+	keys := genKeys()
+	text, bin := &bytes.Buffer{}, &bytes.Buffer{}
+	lib.WriteKeys(text, keys)
+	lib.WriteBinaryKeys(bin, keys, lib.BinaryKeyOptions{})
+
+	for _, data := range []*bytes.Buffer{text, bin} {
+		reader, err := lib.NewKeyFileReader(data)
+		assert.NoError(t, err)
+
+		heap := lib.NewMinHeapArray()
+		added, err := lib.AjoutFrom(heap, reader)
+		assert.NoError(t, err)
+		assert.Equal(t, len(keys), added)
+		assert.NoError(t, heap.Validate())
+		assert.Equal(t, keys[0], heap.SupprMin())
+	}
+
+	reader := lib.NewKeyReader(strings.NewReader(keys[0].Hex() + "\n0x12\n"))
+	heap := lib.NewMinHeapTree()
+	added, err := lib.AjoutFrom(heap, reader)
+	assert.ErrorContains(t, err, "line 2: invalid key \"0x12\"")
+	assert.Equal(t, 1, added)
+}
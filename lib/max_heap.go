@@ -0,0 +1,12 @@
+package lib
+
+// MaxHeap is the counterpart of MinHeap, the greatest key comes out first
+type MaxHeap interface {
+	SupprMax() *KeyInt
+	Ajout(key *KeyInt)
+	AjoutIteratif(keys []*KeyInt)
+	Construction(keys []*KeyInt)
+	String() string
+	Viz() []byte
+	Validate() error
+}
@@ -0,0 +1,42 @@
+package lib
+
+/*
+MaxHeapArray is the counterpart of MinHeapArray, with the same array layout:
+the children of the key at index i are at 2i+1 and 2i+2, and every key is
+inferior or equal to its parent.
+*/
+type MaxHeapArray struct {
+	heapArray
+}
+
+func NewMaxHeapArray() *MaxHeapArray {
+	return newMaxHeapArray(make([]*KeyInt, 0))
+}
+
+// Wrap the keys as they are, the caller heapifies them
+func newMaxHeapArray(keys []*KeyInt) *MaxHeapArray {
+	return &MaxHeapArray{heapArray{array: keys, max: true}}
+}
+
+/*
+Max returns the key with the maximum value without removing it, nil when the
+heap is empty.
+*/
+func (heap *MaxHeapArray) Max() *KeyInt {
+	return heap.top()
+}
+
+/*
+SupprMax removes key with the maximum value.
+*/
+func (heap *MaxHeapArray) SupprMax() *KeyInt {
+	return heap.removeTop()
+}
+
+/*
+Replaces the maximum by the key in a single sift, cheaper than SupprMax
+followed by Ajout; the heap must not be empty.
+*/
+func (heap *MaxHeapArray) replaceMax(key *KeyInt) *KeyInt {
+	return heap.replaceTop(key)
+}
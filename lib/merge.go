@@ -0,0 +1,173 @@
+package lib
+
+import (
+	"io"
+
+	"golang.org/x/exp/slices"
+)
+
+type MergeOptions struct {
+	// Yield a single key of each group of equal keys, the first one
+	Dedup bool
+	// Yield equal keys by increasing source index, in the order of their
+	// source, instead of the order the heap pops them in
+	Stable bool
+	// Heap holding the next key of each source, NewMinHeapArray when nil
+	NewHeap func() MinHeap
+}
+
+// Next key of a source, the queue holds a copy of it so that sources sharing
+// the same keys never put the same pointer twice in the heap
+type mergeHead struct {
+	key    *KeyInt
+	source int
+}
+
+type mergeIterator struct {
+	opts    MergeOptions
+	sources []KeyIterator
+	queue   *PriorityQueue[mergeHead]
+	// keys ready to be returned, filled by a stable merge
+	pending []*KeyInt
+	last    *KeyInt
+	started bool
+	err     error
+}
+
+// Return an iterator over the keys of every source in increasing order,
+// each source being sorted already
+func MergeSorted(sources ...KeyIterator) KeyIterator {
+	return MergeSortedWith(MergeOptions{}, sources...)
+}
+
+// Same as MergeSorted, with options
+func MergeSortedWith(opts MergeOptions, sources ...KeyIterator) KeyIterator {
+	if opts.NewHeap == nil {
+		opts.NewHeap = func() MinHeap { return NewMinHeapArray() }
+	}
+	return &mergeIterator{
+		opts:    opts,
+		sources: sources,
+		queue:   NewPriorityQueue[mergeHead](opts.NewHeap()),
+	}
+}
+
+// Read the next key of the source and push it into the heap
+func (merge *mergeIterator) advance(source int) (*KeyInt, error) {
+	key, err := merge.sources[source].Next()
+	if err != nil {
+		return nil, err
+	}
+	merge.push(mergeHead{key, source})
+	return key, nil
+}
+
+func (merge *mergeIterator) push(head mergeHead) {
+	merge.queue.Ajout(head.key, head)
+}
+
+func (merge *mergeIterator) pop() (mergeHead, bool) {
+	_, head, ok := merge.queue.SupprMin()
+	return head, ok
+}
+
+// Queue every key equal to the smallest one, by source and then in the
+// order of their source
+func (merge *mergeIterator) popGroup() error {
+	first, ok := merge.pop()
+	if !ok {
+		return nil
+	}
+	group := []mergeHead{first}
+	for {
+		head, ok := merge.pop()
+		if !ok {
+			break
+		}
+		if !head.key.Eq(first.key) {
+			merge.push(head)
+			break
+		}
+		group = append(group, head)
+	}
+	// a source has a single key in the heap, the sources are all different
+	slices.SortFunc(group, func(a, b mergeHead) int {
+		return a.source - b.source
+	})
+
+	for _, head := range group {
+		merge.pending = append(merge.pending, head.key)
+		for {
+			key, err := merge.sources[head.source].Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if !key.Eq(first.key) {
+				merge.push(mergeHead{key, head.source})
+				break
+			}
+			merge.pending = append(merge.pending, key)
+		}
+	}
+	return nil
+}
+
+func (merge *mergeIterator) next() (*KeyInt, error) {
+	if len(merge.pending) > 0 {
+		key := merge.pending[0]
+		merge.pending = merge.pending[1:]
+		return key, nil
+	}
+
+	if merge.opts.Stable {
+		if err := merge.popGroup(); err != nil {
+			return nil, err
+		}
+		if len(merge.pending) == 0 {
+			return nil, io.EOF
+		}
+		return merge.next()
+	}
+
+	head, ok := merge.pop()
+	if !ok {
+		return nil, io.EOF
+	}
+	if _, err := merge.advance(head.source); err != nil && err != io.EOF {
+		// return the key anyway, the error comes with the next call
+		merge.err = err
+	}
+	return head.key, nil
+}
+
+func (merge *mergeIterator) Next() (*KeyInt, error) {
+	if !merge.started {
+		merge.started = true
+		for source := range merge.sources {
+			if _, err := merge.advance(source); err != nil && err != io.EOF {
+				merge.err = err
+			}
+		}
+	}
+
+	for {
+		if merge.err != nil {
+			return nil, merge.err
+		}
+		key, err := merge.next()
+		if err != nil {
+			if err != io.EOF {
+				merge.err = err
+			}
+			return nil, err
+		}
+		if merge.opts.Dedup && merge.last != nil && key.Eq(merge.last) {
+			continue
+		}
+		merge.last = key
+		return key, nil
+	}
+}
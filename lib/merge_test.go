@@ -0,0 +1,161 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var mergeHeaps = map[string]func() lib.MinHeap{
+	"heapArray":    func() lib.MinHeap { return lib.NewMinHeapArray() },
+	"heapTree":     func() lib.MinHeap { return lib.NewMinHeapTree() },
+	"heapBinomial": func() lib.MinHeap { return lib.NewMinHeapBinomial() },
+}
+
+func mergeSources(groups [][]*lib.KeyInt) []lib.KeyIterator {
+	sources := make([]lib.KeyIterator, 0, len(groups))
+	for _, keys := range groups {
+		sources = append(sources, lib.IterateKeys(keys))
+	}
+	return sources
+}
+
+// Check that both slices hold the very same pointers, in the same order
+func assertSameKeys(t *testing.T, expected []*lib.KeyInt, actual []*lib.KeyInt, msg string) {
+	assert.Equal(t, len(expected), len(actual), msg)
+	for i := range expected {
+		if i < len(actual) && expected[i] != actual[i] {
+			t.Fatalf("%s: key %d is %v, expected %v", msg, i, actual[i], expected[i])
+		}
+	}
+}
+
+func TestMergeSorted(t *testing.T) {
+	groups := make([][]*lib.KeyInt, 0, 5)
+	all := make([]*lib.KeyInt, 0)
+	for jeu := 1; jeu <= 5; jeu++ {
+		keys := sortedKeys(getKeysFromFile(t, keysDirName+lib.KeyFileName(jeu, 1000)))
+		groups = append(groups, keys)
+		all = append(all, keys...)
+	}
+	// the same keys twice, sharing their pointers
+	groups = append(groups, groups[0])
+	all = append(all, groups[0]...)
+	expected := sortedKeys(all)
+
+	for name, newHeap := range mergeHeaps {
+		merged, err := lib.ReadAllKeys(lib.MergeSortedWith(
+			lib.MergeOptions{NewHeap: newHeap}, mergeSources(groups)...))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, merged, name)
+
+		// sortedKeys is stable, the keys of the first sources come first
+		merged, err = lib.ReadAllKeys(lib.MergeSortedWith(
+			lib.MergeOptions{NewHeap: newHeap, Stable: true}, mergeSources(groups)...))
+		assert.NoError(t, err)
+		assertSameKeys(t, expected, merged, name)
+	}
+
+	merged, err := lib.ReadAllKeys(lib.MergeSorted(mergeSources(groups)...))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, merged)
+}
+
+func TestMergeSortedDuplicates(t *testing.T) {
+	keys := lib.GenKeys(5000, lib.KeyDuplicates, 1)
+	groups := make([][]*lib.KeyInt, 0, 10)
+	for i := 0; i < 10; i++ {
+		groups = append(groups, sortedKeys(keys[i*500:(i+1)*500]))
+	}
+	all := make([]*lib.KeyInt, 0, len(keys))
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	expected := sortedKeys(all)
+
+	unique := make([]*lib.KeyInt, 0)
+	for _, key := range expected {
+		if len(unique) == 0 || !key.Eq(unique[len(unique)-1]) {
+			unique = append(unique, key)
+		}
+	}
+	assert.Less(t, len(unique), 600)
+
+	for name, newHeap := range mergeHeaps {
+		merged, err := lib.ReadAllKeys(lib.MergeSortedWith(
+			lib.MergeOptions{NewHeap: newHeap, Stable: true}, mergeSources(groups)...))
+		assert.NoError(t, err)
+		assertSameKeys(t, expected, merged, name)
+
+		merged, err = lib.ReadAllKeys(lib.MergeSortedWith(
+			lib.MergeOptions{NewHeap: newHeap, Dedup: true}, mergeSources(groups)...))
+		assert.NoError(t, err)
+		assert.Equal(t, unique, merged, name)
+
+		// the first key of each group is the one of the first source
+		merged, err = lib.ReadAllKeys(lib.MergeSortedWith(
+			lib.MergeOptions{NewHeap: newHeap, Dedup: true, Stable: true}, mergeSources(groups)...))
+		assert.NoError(t, err)
+		assertSameKeys(t, unique, merged, name)
+	}
+}
+
+func TestMergeSortedEdges(t *testing.T) {
+	merged, err := lib.ReadAllKeys(lib.MergeSorted())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(merged))
+
+	keys := genKeys()
+	merged, err = lib.ReadAllKeys(lib.MergeSorted(
+		lib.IterateKeys(nil), lib.IterateKeys(keys[3:]), lib.IterateKeys(keys[:3]), lib.IterateKeys(nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, keys, merged)
+
+	for _, stable := range []bool{false, true} {
+		failing := &failingKeyIterator{lib.IterateKeys(keys[1:]), 2}
+		merge := lib.MergeSortedWith(lib.MergeOptions{Stable: stable},
+			lib.IterateKeys(keys[:1]), failing)
+		read := make([]*lib.KeyInt, 0)
+		for {
+			key, err := merge.Next()
+			if err != nil {
+				assert.ErrorContains(t, err, "disk on fire", strconv.FormatBool(stable))
+				_, again := merge.Next()
+				assert.Equal(t, err, again)
+				assert.False(t, errors.Is(err, io.EOF))
+				break
+			}
+			read = append(read, key)
+		}
+		assert.LessOrEqual(t, len(read), 3)
+	}
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkMergeSorted(b *testing.B) {
+	for _, nbSources := range []int{2, 16, 64} {
+		keys := lib.GenKeys(200000, lib.KeyUniform, 1)
+		groups := make([][]*lib.KeyInt, 0, nbSources)
+		size := len(keys) / nbSources
+		for i := 0; i < nbSources; i++ {
+			groups = append(groups, sortedKeys(keys[i*size:(i+1)*size]))
+		}
+		name := "sources_" + strconv.Itoa(nbSources) + "/cles_200000"
+		for _, heapName := range []string{"heapArray", "heapBinomial"} {
+			newHeap := mergeHeaps[heapName]
+			b.Run(heapName+"/"+name, func(b *testing.B) {
+				for n := 0; n < b.N; n++ {
+					lib.ReadAllKeys(lib.MergeSortedWith(
+						lib.MergeOptions{NewHeap: newHeap}, mergeSources(groups)...))
+				}
+			})
+		}
+	}
+}
@@ -7,4 +7,5 @@ type MinHeap interface {
 	Construction(keys []*KeyInt)
 	String() string
 	Viz() []byte
+	Validate() error
 }
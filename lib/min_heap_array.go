@@ -1,198 +1,108 @@
 package lib
 
-type MinHeapArray struct {
-	array []*KeyInt
-}
-
-/*
-Checks if heap is empty.
-*/
-func (heap *MinHeapArray) isEmpty() bool {
-	return len(heap.array) == 0
-}
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
 
-/*
-Checks if an index exists.
-*/
-func (heap *MinHeapArray) isExists(i int) bool {
-	return i < len(heap.array)
+type MinHeapArray struct {
+	heapArray
 }
 
-/*
-Checks if an index has children.
-*/
-func (heap *MinHeapArray) hasChildren(i int) bool {
-	return heap.hasLeftChild(i) && heap.hasRightChild(i)
+func NewMinHeapArray() *MinHeapArray {
+	heap := &MinHeapArray{}
+	heap.array = make([]*KeyInt, 0)
+	return heap
 }
 
 /*
-Checks if an index has left child.
+Min returns the key with the minimum value without removing it, nil when the
+heap is empty.
 */
-func (heap *MinHeapArray) hasLeftChild(i int) bool {
-	return heap.isExists(heap.left(i))
+func (heap *MinHeapArray) Min() *KeyInt {
+	return heap.top()
 }
 
 /*
-Checks if an index has right child.
+Replaces the minimum by the key in a single sift, cheaper than SupprMin
+followed by Ajout; the heap must not be empty.
 */
-func (heap *MinHeapArray) hasRightChild(i int) bool {
-	return heap.isExists(heap.right(i))
+func (heap *MinHeapArray) replaceMin(key *KeyInt) *KeyInt {
+	return heap.replaceTop(key)
 }
 
 /*
-Returns KeyInt from an index.
-*/
-func (heap *MinHeapArray) key(i int) *KeyInt {
-	if heap.isEmpty() {
-		panic("Error: Unable to get root key because heap is empty!")
-	}
-
-	if !heap.isExists(i) {
-		panic("Error: Unable to get key because index match any existing key!")
-	}
-
-	return heap.array[i]
-}
-
-/*
-Returns parent from an index.
+SupprMin removes key with the minimum value.
 */
-func (heap *MinHeapArray) parent(i int) int {
-	return (i - 1) / 2
+func (heap *MinHeapArray) SupprMin() *KeyInt {
+	return heap.removeTop()
 }
 
 /*
-Returns left child from an index.
+Minimum number of keys for ConstructionParallel to spawn goroutines,
+below it the sequential construction is faster.
 */
-func (heap *MinHeapArray) left(i int) int {
-	return (2 * i) + 1
-}
+const parallelConstructionThreshold = 1 << 14
 
 /*
-Returns right child from an index.
-*/
-func (heap *MinHeapArray) right(i int) int {
-	return (2 * i) + 2
-}
+ConstructionParallel
 
-func NewMinHeapArray() *MinHeapArray {
-	heap := &MinHeapArray{}
-	heap.array = make([]*KeyInt, 0)
-	return heap
-}
+Same result as Construction, but the heapify is split between goroutines;
 
-/*
-SupprMin removes key with the minimum value.
+ 1. Pick a depth with enough subtrees to keep every core busy.
+ 2. Heapify each subtree rooted at that depth in its own goroutine,
+    sifting down only moves keys inside a subtree so they never overlap.
+ 3. Sift down the remaining top levels once every subtree is done.
 */
-func (heap *MinHeapArray) SupprMin() *KeyInt {
-	// Check if heap is not empty
-	if heap.isEmpty() {
-		return nil
-	}
-
-	// Swap the min value in the array to last position in the array
-	heap.array[0], heap.array[len(heap.array)-1] = heap.array[len(heap.array)-1], heap.array[0]
-
-	// Store min value
-	minKey := heap.array[len(heap.array)-1]
-
-	// Remove last element
-	heap.array = heap.array[0 : len(heap.array)-1]
-
-	keyIndex := heap.siftDown(0)
-	if keyIndex == -1 {
-		return minKey
-	}
-
-	return nil
-}
-
-func (heap *MinHeapArray) siftDown(keyIndex int) int {
-	var key *KeyInt
-	var leftOrRightKeyIndex int
-	var leftOrRightKey *KeyInt
-
-	if heap.isEmpty() {
-		return -1
-	}
-
-	key = heap.key(keyIndex)
-
-	if heap.hasLeftChild(keyIndex) {
-		leftOrRightKeyIndex = heap.left(keyIndex)
-		leftOrRightKey = heap.key(leftOrRightKeyIndex)
-
-		// Check if there is a right child and if it is smaller than the left child
-		if heap.hasRightChild(keyIndex) {
-			rightKeyIndex := heap.right(keyIndex)
-			rightKey := heap.key(rightKeyIndex)
-
-			if rightKey.Inf(leftOrRightKey) {
-				leftOrRightKeyIndex = rightKeyIndex
-				leftOrRightKey = rightKey
-			}
+func (heap *MinHeapArray) ConstructionParallel(keys []*KeyInt) {
+	heap.array = append(heap.array, keys...)
+
+	// A tracer expects the events in order, keep a traced heap sequential
+	workers := runtime.GOMAXPROCS(0)
+	if workers < 2 || len(heap.array) < parallelConstructionThreshold ||
+		heap.tracer != nil {
+		for i := len(heap.array)/2 - 1; i >= 0; i-- {
+			heap.siftDown(i)
 		}
-
-		// Compare the smaller of the two children with the parent
-		if leftOrRightKey.Inf(key) {
-			heap.array[keyIndex], heap.array[leftOrRightKeyIndex] = leftOrRightKey, key
-
-			// println("after sift down=" + heap.String())
-
-			return heap.siftDown(leftOrRightKeyIndex)
-		}
-	}
-
-	return -1
-}
-
-/*
-Ajout
-
-Add a new element to the end of an array;
-
- 1. Sift up the new element, while heap property is broken.
- 2. Sifting is done as following: compare node's value with parent's value.
-    If they are in wrong order, swap them.
-*/
-func (heap *MinHeapArray) Ajout(key *KeyInt) {
-	heap.array = append(heap.array, key)
-	heap.siftUp(len(heap.array) - 1)
-}
-
-func (heap *MinHeapArray) siftUp(keyIndex int) {
-	if keyIndex == 0 {
 		return
 	}
 
-	key := heap.key(keyIndex)
-	parentKeyIndex := heap.parent(keyIndex)
-	parentKey := heap.key(parentKeyIndex)
-
-	// Check if property is broken
-	if key.Inf(parentKey) {
-		// Swap key and key's parent
-		heap.array[keyIndex], heap.array[parentKeyIndex] = heap.key(parentKeyIndex), heap.key(keyIndex)
-
-		heap.siftUp(parentKeyIndex)
+	// Several subtrees per worker to balance the last incomplete level
+	depth := bits.Len(uint(workers * 4))
+	firstRoot := (1 << depth) - 1
+
+	var wg sync.WaitGroup
+	for root := firstRoot; root < 2*firstRoot+1 && heap.isExists(root); root++ {
+		wg.Add(1)
+		go func(root int) {
+			defer wg.Done()
+			heap.heapifySubtree(root)
+		}(root)
 	}
-}
+	wg.Wait()
 
-func (heap *MinHeapArray) AjoutIteratif(keys []*KeyInt) {
-	for _, key := range keys {
-		heap.Ajout(key)
+	for i := firstRoot - 1; i >= 0; i-- {
+		heap.siftDown(i)
 	}
 }
 
-func (heap *MinHeapArray) Construction(keys []*KeyInt) {
-	// Add every key to array
-	for _, key := range keys {
-		heap.array = append(heap.array, key)
+/*
+Sift down every node of the subtree rooted at the given index, deepest level first.
+*/
+func (heap *MinHeapArray) heapifySubtree(root int) {
+	// Leftmost node of each level of the subtree
+	levels := make([]int, 0, 32)
+	for first := root; heap.hasLeftChild(first); first = heap.left(first) {
+		levels = append(levels, first)
 	}
 
-	// Sift down every tree
-	for i := len(heap.array) / 2; i >= 0; i-- {
-		heap.siftDown(i)
+	for level := len(levels) - 1; level >= 0; level-- {
+		first := levels[level]
+		last := min(first+(1<<level)-1, len(heap.array)-1)
+		for i := last; i >= first; i-- {
+			heap.siftDown(i)
+		}
 	}
 }
 
@@ -209,22 +119,3 @@ func HeapArrayUnion(lhs *MinHeapArray, rhs *MinHeapArray) *MinHeapArray {
 
 	return heap
 }
-
-func (heap *MinHeapArray) String() string {
-	text := "["
-	last := ""
-
-	for _, key := range heap.array {
-		if last != "" {
-			text += last + ", "
-		}
-		last = key.String()
-	}
-
-	text += last + "]"
-	return text
-}
-
-func (heap *MinHeapArray) Viz() []byte {
-	panic(("unimplemented"))
-}
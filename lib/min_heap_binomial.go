@@ -1,11 +1,11 @@
 package lib
 
 import (
-	"bytes"
 	"cmp"
+	"fmt"
+	"io"
 	"math"
 
-	"github.com/bradleyjkemp/memviz"
 	"golang.org/x/exp/constraints"
 	"golang.org/x/exp/slices"
 )
@@ -37,9 +37,18 @@ func max[T constraints.Ordered](a, b T) T {
 	return b
 }
 
+func min[T constraints.Ordered](a, b T) T {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func (tree *BinomialTree) addSubtree(other *BinomialTree) {
 	tree.order = max(other.order, tree.order) + 1
-	tree.children = append(tree.children, other)
+	// the tree may be a copy sharing its children with the original,
+	// capping the capacity makes append allocate instead of overwriting them
+	tree.children = append(tree.children[:len(tree.children):len(tree.children)], other)
 	tree.size += other.size
 }
 
@@ -60,8 +69,9 @@ func BinomialTreeUnion(lhs *BinomialTree, rhs *BinomialTree) *BinomialTree {
  */
 
 type MinHeapBinomial struct {
-	trees []*BinomialTree
-	Size  uint32
+	trees  []*BinomialTree
+	Size   uint32
+	tracer Tracer
 }
 
 func NewMinHeapBinomial() *MinHeapBinomial {
@@ -82,6 +92,43 @@ func NewMinHeapBinomialFromTrees(trees []*BinomialTree) *MinHeapBinomial {
 	}
 }
 
+// Register a function called on every compare, link, cut and alloc, nil disables it
+func (heap *MinHeapBinomial) SetTracer(tracer Tracer) {
+	heap.tracer = tracer
+}
+
+func (heap *MinHeapBinomial) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt,
+	source vizSource) {
+	if heap.tracer != nil {
+		heap.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: source})
+	}
+}
+
+// Trees of a heap being modified, used as the source of trace frames
+type binomialForest []*BinomialTree
+
+func (forest binomialForest) vizForest() []*vizNode {
+	nodes := make([]*vizNode, 0, len(forest))
+	for _, tree := range forest {
+		nodes = append(nodes, tree.vizRoot())
+	}
+	return nodes
+}
+
+// Gather the trees of a union in progress, the non nil merged trees first
+func newBinomialForest(merged []*BinomialTree, others ...[]*BinomialTree) binomialForest {
+	forest := make(binomialForest, 0, len(merged))
+	for _, tree := range merged {
+		if tree != nil {
+			forest = append(forest, tree)
+		}
+	}
+	for _, trees := range others {
+		forest = append(forest, trees...)
+	}
+	return forest
+}
+
 func (heap *MinHeapBinomial) Union(other *MinHeapBinomial) {
 	otherCopy := *other
 	trees := append(heap.trees, otherCopy.trees...)
@@ -94,12 +141,20 @@ func (heap *MinHeapBinomial) Union(other *MinHeapBinomial) {
 	maxOrder = max(maxOrder, 0)
 	merged := make([]*BinomialTree, maxOrder)
 
-	for _, tree := range trees {
+	for i, tree := range trees {
 		order := tree.order
 		for merged[order] != nil {
+			if heap.tracer != nil {
+				heap.trace(TraceCompare, tree.data, merged[order].data,
+					newBinomialForest(merged, []*BinomialTree{tree}, trees[i+1:]))
+			}
 			tree = BinomialTreeUnion(tree, merged[order])
 			merged[order] = nil
 			order += 1
+			if heap.tracer != nil {
+				heap.trace(TraceLink, tree.data, tree.children[len(tree.children)-1].data,
+					newBinomialForest(merged, []*BinomialTree{tree}, trees[i+1:]))
+			}
 		}
 		merged[order] = tree
 	}
@@ -113,11 +168,12 @@ func (heap *MinHeapBinomial) Union(other *MinHeapBinomial) {
 }
 
 func (heap *MinHeapBinomial) Ajout(key *KeyInt) {
-	heap.Union(
-		NewMinHeapBinomialFromTrees(
-			[]*BinomialTree{NewBinomialTree(key)},
-		),
-	)
+	tree := NewBinomialTree(key)
+	if heap.tracer != nil {
+		heap.trace(TraceAlloc, key, nil,
+			newBinomialForest(heap.trees, []*BinomialTree{tree}))
+	}
+	heap.Union(NewMinHeapBinomialFromTrees([]*BinomialTree{tree}))
 }
 
 func (heap *MinHeapBinomial) SupprMin() *KeyInt {
@@ -129,6 +185,9 @@ func (heap *MinHeapBinomial) SupprMin() *KeyInt {
 	minTree := heap.trees[0]
 	minTreeIndex := 0
 	for i, tree := range heap.trees {
+		if i > 0 {
+			heap.trace(TraceCompare, tree.data, minTree.data, heap)
+		}
 		if tree.data.Inf(minTree.data) {
 			minTree = tree
 			minTreeIndex = i
@@ -137,6 +196,10 @@ func (heap *MinHeapBinomial) SupprMin() *KeyInt {
 	heap.trees = append(heap.trees[:minTreeIndex],
 		heap.trees[minTreeIndex+1:]...)
 	heap.Size -= minTree.size
+	if heap.tracer != nil {
+		heap.trace(TraceCut, minTree.data, nil,
+			newBinomialForest(heap.trees, minTree.children))
+	}
 
 	// merge the children of the min tree into the heap list
 	heap.Union(NewMinHeapBinomialFromTrees(minTree.children))
@@ -144,9 +207,11 @@ func (heap *MinHeapBinomial) SupprMin() *KeyInt {
 	return minTree.data
 }
 
-// not needed for binomial min heap
+// same as Construction, the binomial heap has no faster bulk insertion
 func (heap *MinHeapBinomial) AjoutIteratif(keys []*KeyInt) {
-	panic("unimplemented")
+	for _, key := range keys {
+		heap.Ajout(key)
+	}
 }
 
 func (heap *MinHeapBinomial) Construction(keys []*KeyInt) {
@@ -155,6 +220,62 @@ func (heap *MinHeapBinomial) Construction(keys []*KeyInt) {
 	}
 }
 
+/**
+ * Validation
+ */
+
+// Check that the tree is a binomial tree of its order: children of orders
+// 0 to order-1, 2^order keys, and no key inferior to its parent
+func (tree *BinomialTree) Validate() error {
+	if tree.data == nil {
+		return fmt.Errorf("nil key in tree of order %d", tree.order)
+	}
+	if len(tree.children) != int(tree.order) {
+		return fmt.Errorf("tree %v of order %d has %d children",
+			tree.data, tree.order, len(tree.children))
+	}
+	if tree.size != 1<<tree.order {
+		return fmt.Errorf("tree %v of order %d has size %d",
+			tree.data, tree.order, tree.size)
+	}
+
+	for i, child := range tree.children {
+		if child.order != uint32(i) {
+			return fmt.Errorf("child %d of tree %v has order %d", i, tree.data, child.order)
+		}
+		if child.data != nil && child.data.Inf(tree.data) {
+			return fmt.Errorf("heap order broken: %v is inferior to its parent %v",
+				child.data, tree.data)
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check every tree of the heap, that there is at most one tree per order
+// in increasing order, and that Size is the number of keys
+func (heap *MinHeapBinomial) Validate() error {
+	var size uint32 = 0
+	for i, tree := range heap.trees {
+		if i > 0 && tree.order <= heap.trees[i-1].order {
+			return fmt.Errorf("tree %d of order %d follows a tree of order %d",
+				i, tree.order, heap.trees[i-1].order)
+		}
+		if err := tree.Validate(); err != nil {
+			return err
+		}
+		size += tree.size
+	}
+
+	if size != heap.Size {
+		return fmt.Errorf("heap holds %d keys but its size is %d", size, heap.Size)
+	}
+	return nil
+}
+
 /**
  * Heap Vizualisation
  */
@@ -171,10 +292,22 @@ func (heap *MinHeapBinomial) String() string {
 	return text
 }
 
+func (heap *MinHeapBinomial) vizForest() []*vizNode {
+	return binomialForest(heap.trees).vizForest()
+}
+
 func (heap *MinHeapBinomial) Viz() []byte {
-	buf := &bytes.Buffer{}
-	memviz.Map(buf, heap)
-	return buf.Bytes()
+	return heap.VizFormat(KeyString)
+}
+
+// Return the root list as a DOT graph, each root is annotated with its order
+func (heap *MinHeapBinomial) VizFormat(format KeyFormatter) []byte {
+	return vizDot(heap.vizForest(), vizOptions{format: format})
+}
+
+// Draw the root list in the terminal, each root is followed by its order
+func (heap *MinHeapBinomial) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, heap.vizForest(), opts)
 }
 
 /**
@@ -197,8 +330,24 @@ func (tree *BinomialTree) String() string {
 	return text
 }
 
+func (tree *BinomialTree) vizNode() *vizNode {
+	node := &vizNode{key: tree.data, children: make([]*vizNode, 0, len(tree.children))}
+	for _, child := range tree.children {
+		node.children = append(node.children, child.vizNode())
+	}
+	return node
+}
+
+func (tree *BinomialTree) vizRoot() *vizNode {
+	node := tree.vizNode()
+	node.note = fmt.Sprintf("B%d", tree.order)
+	return node
+}
+
 func (tree *BinomialTree) Viz() []byte {
-	buf := &bytes.Buffer{}
-	memviz.Map(buf, tree)
-	return buf.Bytes()
+	return tree.VizFormat(KeyString)
+}
+
+func (tree *BinomialTree) VizFormat(format KeyFormatter) []byte {
+	return vizDot([]*vizNode{tree.vizRoot()}, vizOptions{format: format})
 }
@@ -12,16 +12,21 @@ func TestBinomialTreeUnion(t *testing.T) {
 
 	tree := lib.NewBinomialTree(keys[3])
 	assert.Equal(t, "(0-40)", tree.String())
+	assert.NoError(t, tree.Validate())
 	tree = lib.BinomialTreeUnion(tree, lib.NewBinomialTree(keys[2]))
 	assert.Equal(t, "(0-30, (0-40))", tree.String())
+	assert.NoError(t, tree.Validate())
 
 	second_tree := lib.NewBinomialTree(keys[1])
 	assert.Equal(t, "(0-20)", second_tree.String())
+	assert.NoError(t, second_tree.Validate())
 	second_tree = lib.BinomialTreeUnion(second_tree, lib.NewBinomialTree(keys[0]))
 	assert.Equal(t, "(0-10, (0-20))", second_tree.String())
+	assert.NoError(t, second_tree.Validate())
 
 	tree = lib.BinomialTreeUnion(tree, second_tree)
 	assert.Equal(t, "(0-10, (0-20), (0-30, (0-40)))", tree.String())
+	assert.NoError(t, tree.Validate())
 	vizBytes(tree.Viz(), "binom_tree")
 }
 
@@ -34,28 +39,37 @@ func TestBinomialAjout(t *testing.T) {
 
 	heap := lib.NewMinHeapBinomial()
 	assert.Equal(t, "[]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[7])
 	assert.Equal(t, "[(0-80)]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[6])
 	assert.Equal(t, "[(0-70, (0-80))]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[5])
 	assert.Equal(t, "[(0-60), (0-70, (0-80))]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[4])
 	assert.Equal(t, "[(0-50, (0-60), (0-70, (0-80)))]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[3])
 	assert.Equal(t, "[(0-40), (0-50, (0-60), (0-70, (0-80)))]", heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[2])
 	assert.Equal(t,
 		"[(0-30, (0-40)), (0-50, (0-60), (0-70, (0-80)))]",
 		heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[1])
 	assert.Equal(t,
 		"[(0-20), (0-30, (0-40)), (0-50, (0-60), (0-70, (0-80)))]",
 		heap.String())
+	assert.NoError(t, heap.Validate())
 	heap.Ajout(keys[0])
 	assert.Equal(t,
 		"[(0-10, (0-20), (0-30, (0-40)), (0-50, (0-60), (0-70, (0-80))))]",
 		heap.String())
+	assert.NoError(t, heap.Validate())
 }
 
 func TestBinomialContruction(t *testing.T) {
@@ -63,10 +77,11 @@ func TestBinomialContruction(t *testing.T) {
 	heap := lib.NewMinHeapBinomial()
 	heap.Construction(keys)
 	assert.Equal(t, "[(0-50), (0-10, (0-20), (0-30, (0-40)))]", heap.String())
+	assert.NoError(t, heap.Validate())
 }
 
 func TestBinomialUnion(t *testing.T) {
-	keys := append(genKeys())
+	keys := genKeys()
 
 	heaps1 := lib.NewMinHeapBinomial()
 	heaps1.Ajout(keys[0])
@@ -86,12 +101,56 @@ func TestBinomialUnion(t *testing.T) {
 	heaps3_1.Ajout(lib.NewKeyInt(0, 100))
 	heaps3.Union(heaps3_1)
 	assert.Equal(t, "[(0-100), (0-40, (0-50))]", heaps3.String())
+	assert.NoError(t, heaps3.Validate())
 	vizBytes(heaps3.Viz(), "binomial_heap_merge")
 
 	heaps1.Union(heaps2)
 	assert.Equal(t, "[(0-30), (0-10, (0-20))]", heaps1.String())
+	assert.NoError(t, heaps1.Validate())
 
 	heaps1.Union(heaps3)
 	assert.Equal(t, "[(0-40, (0-50)), (0-10, (0-20), (0-30, (0-100)))]",
 		heaps1.String())
+	assert.NoError(t, heaps1.Validate())
+}
+
+func TestBinomialValidate(t *testing.T) {
+	keys := genKeys()
+
+	// linking trees of different orders does not give a binomial tree
+	tree := lib.BinomialTreeUnion(lib.NewBinomialTree(keys[0]), lib.NewBinomialTree(keys[1]))
+	tree = lib.BinomialTreeUnion(tree, lib.NewBinomialTree(keys[2]))
+	assert.EqualError(t, tree.Validate(), "tree 0-10 of order 2 has size 3")
+
+	heap := lib.NewMinHeapBinomialFromTrees([]*lib.BinomialTree{
+		lib.NewBinomialTree(keys[1]),
+		lib.NewBinomialTree(keys[0]),
+	})
+	assert.EqualError(t, heap.Validate(), "tree 1 of order 0 follows a tree of order 0")
+}
+
+func TestBinomialUnionShared(t *testing.T) {
+	genKeysFrom := func(u1 uint64) []*lib.KeyInt {
+		keys := make([]*lib.KeyInt, 0, 8)
+		for i := uint64(1); i <= 8; i++ {
+			keys = append(keys, lib.NewKeyInt(u1, i))
+		}
+		return keys
+	}
+
+	// a single tree of order 3, whose children slice has room for a 4th child
+	shared := lib.NewMinHeapBinomial()
+	shared.Construction(genKeysFrom(0))
+
+	heap1 := lib.NewMinHeapBinomial()
+	heap1.Construction(genKeysFrom(1))
+	heap2 := lib.NewMinHeapBinomial()
+	heap2.Construction(genKeysFrom(2))
+
+	heap1.Union(shared)
+	expected := heap1.String()
+	heap2.Union(shared)
+	assert.Equal(t, expected, heap1.String())
+	assert.Equal(t, "[(0-1, (0-2), (0-3, (0-4)), (0-5, (0-6), (0-7, (0-8))))]", shared.String())
+	assert.NoError(t, heap1.Validate())
 }
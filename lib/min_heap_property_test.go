@@ -0,0 +1,328 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+/**
+ * Operations
+ */
+
+type heapOpKind int
+
+const (
+	opAjout heapOpKind = iota
+	opAjoutIteratif
+	opConstruction
+	opUnion
+	opSupprMin
+	nbHeapOpKinds
+)
+
+var heapOpNames = []string{"Ajout", "AjoutIteratif", "Construction", "Union", "SupprMin"}
+
+// A single operation applied to every heap, keys are ignored by SupprMin
+// and Ajout only uses the first one
+type heapOp struct {
+	kind heapOpKind
+	keys []*lib.KeyInt
+}
+
+func (op heapOp) String() string {
+	keys := make([]string, 0, len(op.keys))
+	for _, key := range op.keys {
+		keys = append(keys, key.String())
+	}
+	return heapOpNames[op.kind] + "(" + strings.Join(keys, ", ") + ")"
+}
+
+func formatHeapOps(ops []heapOp) string {
+	text := ""
+	for i, op := range ops {
+		text += fmt.Sprintf("\n  %d: %v", i, op)
+	}
+	return text
+}
+
+// Keys from a small range so that equal keys are frequent
+func genHeapOpKey(r *rand.Rand) *lib.KeyInt {
+	return lib.NewKeyInt(uint64(r.Intn(3)), uint64(r.Intn(32)))
+}
+
+func genHeapOps(r *rand.Rand, nbOps int) []heapOp {
+	ops := make([]heapOp, 0, nbOps)
+	for i := 0; i < nbOps; i++ {
+		op := heapOp{kind: heapOpKind(r.Intn(int(nbHeapOpKinds)))}
+		nbKeys := 0
+		switch op.kind {
+		case opAjout:
+			nbKeys = 1
+		case opAjoutIteratif, opConstruction, opUnion:
+			nbKeys = r.Intn(12)
+		}
+		for j := 0; j < nbKeys; j++ {
+			op.keys = append(op.keys, genHeapOpKey(r))
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Decode fuzzer input, one byte for the kind, one for the number of keys,
+// then one byte per key; long inputs are cut to keep each run fast
+func decodeHeapOps(data []byte) []heapOp {
+	ops := make([]heapOp, 0)
+	for len(data) >= 2 && len(ops) < 64 {
+		op := heapOp{kind: heapOpKind(data[0] % byte(nbHeapOpKinds))}
+		nbKeys := int(data[1] % 16)
+		if op.kind == opAjout {
+			nbKeys = 1
+		}
+		data = data[2:]
+		for j := 0; j < nbKeys && len(data) > 0; j++ {
+			op.keys = append(op.keys, lib.NewKeyInt(uint64(data[0]>>6), uint64(data[0]&63)))
+			data = data[1:]
+		}
+		if op.kind == opAjout && len(op.keys) == 0 {
+			break
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+/**
+ * Harness
+ */
+
+// How to build and merge one of the heap implementations
+type heapImpl struct {
+	name  string
+	new   func() lib.MinHeap
+	union func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap
+}
+
+var heapImpls = []heapImpl{
+	{
+		name: "heapArray",
+		new:  func() lib.MinHeap { return lib.NewMinHeapArray() },
+		union: func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap {
+			return lib.HeapArrayUnion(lhs.(*lib.MinHeapArray), rhs.(*lib.MinHeapArray))
+		},
+	},
+	{
+		name: "heapTree",
+		new:  func() lib.MinHeap { return lib.NewMinHeapTree() },
+		union: func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap {
+			return lib.HeapTreeUnion(lhs.(*lib.MinHeapTree), rhs.(*lib.MinHeapTree))
+		},
+	},
+	{
+		name: "heapBinomial",
+		new:  func() lib.MinHeap { return lib.NewMinHeapBinomial() },
+		union: func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap {
+			lhs.(*lib.MinHeapBinomial).Union(rhs.(*lib.MinHeapBinomial))
+			return lhs
+		},
+	},
+	{
+		name: "heapMinMax",
+		new:  func() lib.MinHeap { return lib.NewMinMaxHeapArray() },
+		union: func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap {
+			return lib.HeapMinMaxUnion(lhs.(*lib.MinMaxHeapArray), rhs.(*lib.MinMaxHeapArray))
+		},
+	},
+}
+
+// Reference model, the keys kept sorted
+type heapModel struct {
+	keys []*lib.KeyInt
+}
+
+func (model *heapModel) add(keys ...*lib.KeyInt) {
+	for _, key := range keys {
+		i, _ := slices.BinarySearchFunc(model.keys, key, func(a, b *lib.KeyInt) int {
+			if a.Inf(b) || a.Eq(b) {
+				return -1
+			}
+			return 1
+		})
+		model.keys = slices.Insert(model.keys, i, key)
+	}
+}
+
+func (model *heapModel) supprMin() *lib.KeyInt {
+	if len(model.keys) == 0 {
+		return nil
+	}
+	key := model.keys[0]
+	model.keys = model.keys[1:]
+	return key
+}
+
+func checkSupprMin(step int, expected *lib.KeyInt, actual *lib.KeyInt) error {
+	if expected == nil && actual == nil {
+		return nil
+	}
+	if expected == nil || actual == nil || !expected.Eq(actual) {
+		return fmt.Errorf("step %d: SupprMin returned %v, expected %v", step, actual, expected)
+	}
+	return nil
+}
+
+// Apply the operations to one implementation and the model, return the
+// first divergence or broken invariant
+func runHeapOps(impl heapImpl, ops []heapOp) error {
+	heap := impl.new()
+	model := &heapModel{}
+
+	for step, op := range ops {
+		switch op.kind {
+		case opAjout:
+			heap.Ajout(op.keys[0])
+			model.add(op.keys[0])
+		case opAjoutIteratif:
+			heap.AjoutIteratif(op.keys)
+			model.add(op.keys...)
+		case opConstruction:
+			heap.Construction(op.keys)
+			model.add(op.keys...)
+		case opUnion:
+			other := impl.new()
+			other.Construction(op.keys)
+			heap = impl.union(heap, other)
+			model.add(op.keys...)
+			// the merged heap must not share anything with the source
+			// that a later use of the source could change
+			other.Ajout(genKeys()[0])
+			impl.union(impl.union(impl.new(), other), other)
+		case opSupprMin:
+			if err := checkSupprMin(step, model.supprMin(), heap.SupprMin()); err != nil {
+				return err
+			}
+		}
+		if err := heap.Validate(); err != nil {
+			return fmt.Errorf("step %d: %v", step, err)
+		}
+	}
+
+	// drain what is left
+	for {
+		expected := model.supprMin()
+		if err := checkSupprMin(len(ops), expected, heap.SupprMin()); err != nil {
+			return err
+		}
+		if expected == nil {
+			return nil
+		}
+	}
+}
+
+// Return a smaller sequence of operations that still fails: drop chunks of
+// operations, then keys inside the remaining operations, until nothing goes
+func shrinkHeapOps(impl heapImpl, ops []heapOp) []heapOp {
+	fails := func(candidate []heapOp) bool {
+		return runHeapOps(impl, candidate) != nil
+	}
+
+	for progress := true; progress; {
+		progress = false
+
+		for chunk := len(ops) / 2; chunk > 0; chunk /= 2 {
+			for start := 0; start+chunk <= len(ops); {
+				candidate := append(slices.Clone(ops[:start]), ops[start+chunk:]...)
+				if fails(candidate) {
+					ops = candidate
+					progress = true
+				} else {
+					start++
+				}
+			}
+		}
+
+		for i := range ops {
+			for j := 0; j < len(ops[i].keys) && ops[i].kind != opAjout; {
+				candidate := slices.Clone(ops)
+				candidate[i].keys = slices.Delete(slices.Clone(ops[i].keys), j, j+1)
+				if fails(candidate) {
+					ops = candidate
+					progress = true
+				} else {
+					j++
+				}
+			}
+		}
+	}
+
+	return ops
+}
+
+func checkHeapOps(t *testing.T, ops []heapOp) {
+	for _, impl := range heapImpls {
+		if err := runHeapOps(impl, ops); err != nil {
+			minimal := shrinkHeapOps(impl, ops)
+			t.Fatalf("%s: %v\nminimal failing sequence:%s",
+				impl.name, runHeapOps(impl, minimal), formatHeapOps(minimal))
+		}
+	}
+}
+
+/**
+ * Tests
+ */
+
+func TestHeapsRandomized(t *testing.T) {
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		checkHeapOps(t, genHeapOps(r, 1+r.Intn(60)))
+	}
+}
+
+func TestShrinkHeapOps(t *testing.T) {
+	// a heap that forgets every key but the first one of AjoutIteratif
+	broken := heapImpl{
+		name: "broken",
+		new:  func() lib.MinHeap { return &forgetfulHeap{lib.NewMinHeapArray()} },
+		union: func(lhs lib.MinHeap, rhs lib.MinHeap) lib.MinHeap {
+			return &forgetfulHeap{lib.HeapArrayUnion(
+				lhs.(*forgetfulHeap).MinHeapArray, rhs.(*forgetfulHeap).MinHeapArray)}
+		},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	ops := genHeapOps(r, 40)
+	ops = append(ops, heapOp{opAjoutIteratif, genKeys()[:2]})
+	ops = append(ops, genHeapOps(r, 40)...)
+
+	minimal := shrinkHeapOps(broken, ops)
+	if len(minimal) != 1 || minimal[0].kind != opAjoutIteratif || len(minimal[0].keys) != 2 {
+		t.Fatalf("sequence not shrunk:%s", formatHeapOps(minimal))
+	}
+}
+
+type forgetfulHeap struct {
+	*lib.MinHeapArray
+}
+
+func (heap *forgetfulHeap) AjoutIteratif(keys []*lib.KeyInt) {
+	if len(keys) > 0 {
+		heap.Ajout(keys[0])
+	}
+}
+
+func FuzzHeaps(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 0, 10, 0, 0, 5, 4, 0})
+	f.Add([]byte{2, 5, 9, 8, 7, 6, 5, 4, 0, 3, 3, 1, 2, 3, 4, 0, 4, 0, 4, 0})
+	f.Add([]byte{1, 4, 200, 100, 50, 25, 4, 0, 3, 2, 150, 10, 4, 0, 4, 0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkHeapOps(t, decodeHeapOps(data))
+	})
+}
@@ -2,12 +2,15 @@ package lib_test
 
 import (
 	"arithmos/lib"
-	"bufio"
 	"bytes"
+	"flag"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,10 +19,29 @@ import (
 
 const keysDirName = "../data/cles_alea/"
 
+// go test ./lib -bench . -args -keys <dir> runs the benchmarks on other key
+// files, like the binary ones written by the convert command
+var benchKeysDir = flag.String("keys", keysDirName,
+	"directory of the key files of the benchmarks, text or binary")
+
+// Return the key file of the benchmarks with the given number, the binary
+// one when there is no text one
+func benchKeysPath(jeu int, nbKeys int) string {
+	path := filepath.Join(*benchKeysDir, lib.KeyFileName(jeu, nbKeys))
+	if _, err := os.Stat(path); err != nil {
+		return strings.TrimSuffix(path, ".txt") + lib.BinaryKeyExt
+	}
+	return path
+}
+
 func vizBytes(data []byte, filename string) {
 	DirPath := "../test-output/"
 	_ = os.Mkdir(DirPath, 0755)
 	path := DirPath + filename
+	// rendering is optional, graphviz is not installed everywhere
+	if _, err := exec.LookPath("dot"); err != nil {
+		return
+	}
 	cmd := exec.Command("dot", "-Tpng", "-Gdpi=300", "-o", path+".png")
 	cmd.Stdin = bytes.NewReader(data)
 	err := cmd.Run()
@@ -52,21 +74,18 @@ func runTestHeaps(test func(lib.MinHeap), withBinomial bool) {
 	}
 }
 
-func getKeysFromFile(path string) []*lib.KeyInt {
+// Read every key of a text or binary key file, failing the test on error
+func getKeysFromFile(tb testing.TB, path string) []*lib.KeyInt {
+	tb.Helper()
 	f, err := os.Open(path)
 	if err != nil {
-		panic(err)
+		tb.Fatal(err)
 	}
 	defer f.Close()
 
-	keys := make([]*lib.KeyInt, 0, 80000)
-	s := bufio.NewScanner(f)
-	for s.Scan() {
-		keyInt, err := lib.NewKeyIntFromString(s.Text())
-		if err != nil {
-			panic(err)
-		}
-		keys = append(keys, keyInt)
+	keys, err := lib.ReadKeys(f)
+	if err != nil {
+		tb.Fatalf("%s: %v", path, err)
 	}
 
 	return keys
@@ -89,16 +108,22 @@ func TestAjoutAscending(t *testing.T) {
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		assert.Equal(t, "[]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[0])
 		assert.Equal(t, "[0-10]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[1])
 		assert.Equal(t, "[0-10, 0-20]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[2])
 		assert.Equal(t, "[0-10, 0-20, 0-30]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[4])
 		assert.Equal(t, "[0-10, 0-20, 0-30, 0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[3])
 		assert.Equal(t, "[0-10, 0-20, 0-30, 0-50, 0-40]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 }
 
@@ -106,16 +131,22 @@ func TestAjoutDescending(t *testing.T) {
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		assert.Equal(t, "[]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[4])
 		assert.Equal(t, "[0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[3])
 		assert.Equal(t, "[0-40, 0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[2])
 		assert.Equal(t, "[0-30, 0-50, 0-40]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[0])
 		assert.Equal(t, "[0-10, 0-30, 0-40, 0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[1])
 		assert.Equal(t, "[0-10, 0-20, 0-40, 0-50, 0-30]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 }
 
@@ -123,14 +154,17 @@ func TestAjoutIteratif(t *testing.T) {
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		assert.Equal(t, "[]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.AjoutIteratif(keys)
 		assert.Equal(t, "[0-10, 0-20, 0-30, 0-40, 0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		slices.Reverse(keys)
 		heap.AjoutIteratif(keys)
 		assert.Equal(t, "[0-10, 0-20, 0-40, 0-50, 0-30]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 }
 
@@ -139,12 +173,15 @@ func TestConstruction(t *testing.T) {
 		keys := genKeys()
 		heap.Construction(keys[:0])
 		assert.Equal(t, "[]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		assert.Equal(t, "[]", heap.String())
+		assert.NoError(t, heap.Validate())
 		heap.Construction(keys)
 		assert.Equal(t, "[0-10, 0-20, 0-30, 0-40, 0-50]", heap.String())
+		assert.NoError(t, heap.Validate())
 	}, false)
 }
 
@@ -158,6 +195,7 @@ func TestUnion(t *testing.T) {
 
 	heap := lib.HeapTreeUnion(heap1, heap2)
 	assert.Equal(t, "[0-10, 0-20, 0-30, 0-40, 0-50]", heap.String())
+	assert.NoError(t, heap.Validate())
 }
 
 func TestSupprMin(t *testing.T) {
@@ -165,13 +203,16 @@ func TestSupprMin(t *testing.T) {
 		keys := genKeys()
 		heap.Ajout(keys[0])
 		assert.Equal(t, keys[0], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 	}, false)
 }
 
 func TestSupprMinEmpty(t *testing.T) {
 	runTestHeaps(func(heap lib.MinHeap) {
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 	}, true)
 }
 
@@ -184,35 +225,53 @@ func TestSupprMultiple(t *testing.T) {
 		heap.Ajout(keys[1])
 		heap.Ajout(keys[0])
 		assert.Equal(t, keys[0], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[1], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[2], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[3], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[4], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		heap.Ajout(keys[0])
 		heap.Ajout(keys[1])
 		assert.Equal(t, keys[0], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[1], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 	}, true)
 	runTestHeaps(func(heap lib.MinHeap) {
 		keys := genKeys()
 		heap.Construction(keys)
 		assert.Equal(t, keys[0], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[1], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[2], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[3], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[4], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		heap.Construction(keys[:2])
 		assert.Equal(t, keys[0], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Equal(t, keys[1], heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 		assert.Nil(t, heap.SupprMin())
+		assert.NoError(t, heap.Validate())
 	}, true)
 }
 
 func TestSupprFile(t *testing.T) {
-	keys := getKeysFromFile(keysDirName + "jeu_1_nb_cles_1000.txt")
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
 
 	heapArray := lib.NewMinHeapArray()
 	heapArray.AjoutIteratif(keys)
@@ -235,15 +294,44 @@ func TestSupprFile(t *testing.T) {
 	heapBinomial := lib.NewMinHeapBinomial()
 	heapBinomial.Construction(keys)
 
+	heaps := []lib.MinHeap{heapArray, heapArrayCons, heapTree, heapTreeCons, heapBinomial}
 	for i := 0; i < len(keys); i++ {
 		binoMin := heapBinomial.SupprMin()
 		assert.Equal(t, heapArray.SupprMin(), binoMin)
 		assert.Equal(t, heapArrayCons.SupprMin(), binoMin)
 		assert.Equal(t, heapTree.SupprMin(), binoMin)
 		assert.Equal(t, heapTreeCons.SupprMin(), binoMin)
+		if i%100 == 0 {
+			for _, heap := range heaps {
+				assert.NoError(t, heap.Validate())
+			}
+		}
 	}
 }
 
+func TestConstructionParallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_120000.txt")
+
+	heap := lib.NewMinHeapArray()
+	heap.Construction(keys)
+
+	heapParallel := lib.NewMinHeapArray()
+	heapParallel.ConstructionParallel(keys)
+	assert.NoError(t, heapParallel.Validate())
+
+	heapSmall := lib.NewMinHeapArray()
+	heapSmall.ConstructionParallel(genKeys())
+	assert.Equal(t, "[0-10, 0-20, 0-30, 0-40, 0-50]", heapSmall.String())
+	assert.NoError(t, heapSmall.Validate())
+
+	for i := 0; i < len(keys); i++ {
+		assert.Equal(t, heap.SupprMin(), heapParallel.SupprMin())
+	}
+	assert.Nil(t, heapParallel.SupprMin())
+}
+
 /**
  * Benchmarks
  */
@@ -274,12 +362,18 @@ func benchmarkHeaps(
 	}
 
 	debug.SetGCPercent(800)
-	dirEntries, err := os.ReadDir(keysDirName)
+	dirEntries, err := os.ReadDir(*benchKeysDir)
 	if err != nil {
 		panic(err)
 	}
 	for _, entry := range dirEntries {
-		keys := getKeysFromFile(keysDirName + entry.Name())
+		// a key set in both formats is only run once, on the text file
+		if name, ok := strings.CutSuffix(entry.Name(), lib.BinaryKeyExt); ok {
+			if _, err := os.Stat(filepath.Join(*benchKeysDir, name+".txt")); err == nil {
+				continue
+			}
+		}
+		keys := getKeysFromFile(b, filepath.Join(*benchKeysDir, entry.Name()))
 		run(entry.Name(), keys)
 	}
 
@@ -307,6 +401,27 @@ func BenchmarkConstruction(b *testing.B) {
 	}, true)
 }
 
+func BenchmarkConstructionParallel(b *testing.B) {
+	run := func(nbKeys uint64) {
+		keys := genDescendingKeys(nbKeys)
+		name := "extra_jeu_nb_cles_" + strconv.FormatUint(nbKeys, 10)
+		b.Run("heapArray/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				lib.NewMinHeapArray().Construction(keys)
+			}
+		})
+		b.Run("heapArrayParallel/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				lib.NewMinHeapArray().ConstructionParallel(keys)
+			}
+		})
+	}
+
+	debug.SetGCPercent(800)
+	run(500000)
+	run(1000000)
+}
+
 /**
  * Union benchmarks
  */
@@ -354,9 +469,7 @@ func BenchmarkUnion(b *testing.B) {
 
 	for _, dataSize := range dataSizes {
 		keysGroups := make([][]*lib.KeyInt, 0, 2)
-		path := keysDirName + "jeu_1" + "_nb_cles_" +
-			strconv.Itoa(dataSize) + ".txt"
-		keys := getKeysFromFile(path)
+		keys := getKeysFromFile(b, benchKeysPath(1, dataSize))
 
 		keysGroups = append(keysGroups, keys[dataSize/2:])
 		keysGroups = append(keysGroups, keys[:dataSize/2])
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"io"
 	"math/bits"
 
 	"golang.org/x/exp/slices"
@@ -19,9 +20,10 @@ func (node *MinHeapNode) isNil() bool {
 }
 
 type MinHeapTree struct {
-	root *MinHeapNode
-	size uint32
-	path []byte
+	root   *MinHeapNode
+	size   uint32
+	path   []byte
+	tracer Tracer
 }
 
 func NewMinHeapTree() *MinHeapTree {
@@ -32,14 +34,27 @@ func NewMinHeapTree() *MinHeapTree {
 	}
 }
 
+// Register a function called on every compare, swap, cut and alloc, nil disables it
+func (heap *MinHeapTree) SetTracer(tracer Tracer) {
+	heap.tracer = tracer
+}
+
+func (heap *MinHeapTree) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt) {
+	if heap.tracer != nil {
+		heap.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: heap})
+	}
+}
+
 // Swap the given node with its parent recursivly
 // For example, if we insert a low key at the bottom, it will raise it to the top
-func (*MinHeapTree) bubbleUpNode(node *MinHeapNode) {
+func (heap *MinHeapTree) bubbleUpNode(node *MinHeapNode) {
 	currNode := node
 	for !currNode.parent.isNil() {
 		parentNode := currNode.parent
+		heap.trace(TraceCompare, currNode.data, parentNode.data)
 		if currNode.data.Inf(parentNode.data) {
 			currNode.data, parentNode.data = parentNode.data, currNode.data
+			heap.trace(TraceSwap, parentNode.data, currNode.data)
 		}
 		currNode = parentNode
 	}
@@ -95,6 +110,7 @@ func (heap *MinHeapTree) addAtEnd(key *KeyInt) *MinHeapNode {
 		beforeLastNode.right = &MinHeapNode{data: key, parent: beforeLastNode}
 		insertedNode = beforeLastNode.right
 	}
+	heap.trace(TraceAlloc, key, nil)
 
 	if insertedNode.isNil() {
 		panic("Failed to insert node in min heap tree")
@@ -170,17 +186,27 @@ func HeapTreeUnion(lhs *MinHeapTree, rhs *MinHeapTree) *MinHeapTree {
 func (heap *MinHeapTree) sinkNode(node *MinHeapNode) {
 	var minNode *MinHeapNode
 
-	if !node.left.isNil() && node.left.data.Inf(node.data) {
-		minNode = node.left
+	if !node.left.isNil() {
+		heap.trace(TraceCompare, node.left.data, node.data)
+		if node.left.data.Inf(node.data) {
+			minNode = node.left
+		}
 	}
-	if !node.right.isNil() && node.right.data.Inf(node.data) {
-		if minNode == nil || (minNode != nil && node.right.data.Inf(minNode.data)) {
-			minNode = node.right
+	if !node.right.isNil() {
+		heap.trace(TraceCompare, node.right.data, node.data)
+		if node.right.data.Inf(node.data) {
+			if minNode != nil {
+				heap.trace(TraceCompare, node.right.data, minNode.data)
+			}
+			if minNode == nil || (minNode != nil && node.right.data.Inf(minNode.data)) {
+				minNode = node.right
+			}
 		}
 	}
 
 	if minNode != nil {
 		minNode.data, node.data = node.data, minNode.data
+		heap.trace(TraceSwap, node.data, minNode.data)
 		heap.sinkNode(minNode)
 	}
 }
@@ -193,8 +219,12 @@ func (heap *MinHeapTree) SupprMin() *KeyInt {
 
 	// extract root data, then swap it with the last heap node
 	data := heap.root.data
-	heap.root.data = last.data
+	if last != heap.root {
+		heap.root.data, last.data = last.data, heap.root.data
+		heap.trace(TraceSwap, heap.root.data, data)
+	}
 	last.data = nil
+	heap.trace(TraceCut, data, nil)
 
 	heap.sinkNode(heap.root)
 	heap.size -= 1
@@ -202,6 +232,57 @@ func (heap *MinHeapTree) SupprMin() *KeyInt {
 	return data
 }
 
+/**
+ * Validation
+ */
+
+// Check a node at the given level order position (1 for the root), the nodes
+// after the last one may remain allocated but must be empty
+func (heap *MinHeapTree) validateNode(node *MinHeapNode, position uint64) error {
+	if node == nil {
+		if position <= uint64(heap.size) {
+			return fmt.Errorf("missing node at position %d, size is %d", position, heap.size)
+		}
+		return nil
+	}
+	if node.data == nil && position <= uint64(heap.size) {
+		return fmt.Errorf("empty node at position %d, size is %d", position, heap.size)
+	}
+	if node.data != nil && position > uint64(heap.size) {
+		return fmt.Errorf("key %v at position %d past the size %d", node.data, position, heap.size)
+	}
+
+	for i, child := range []*MinHeapNode{node.left, node.right} {
+		if child == nil {
+			continue
+		}
+		if child.parent != node {
+			return fmt.Errorf("node at position %d has a wrong parent", 2*position+uint64(i))
+		}
+		if node.data != nil && !child.isNil() && child.data.Inf(node.data) {
+			return fmt.Errorf("heap order broken at position %d: %v is inferior to its parent %v",
+				2*position+uint64(i), child.data, node.data)
+		}
+		if err := heap.validateNode(child, 2*position+uint64(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check the heap order, that the tree is complete with size nodes and that
+// every parent pointer is right
+func (heap *MinHeapTree) Validate() error {
+	if heap.root == nil {
+		return fmt.Errorf("missing root")
+	}
+	if heap.root.parent != nil {
+		return fmt.Errorf("root has a parent")
+	}
+	return heap.validateNode(heap.root, 1)
+}
+
 /**
 * Vizualisation
  */
@@ -245,25 +326,31 @@ func (heap *MinHeapTree) String() string {
 	return text
 }
 
+func (heap *MinHeapTree) vizNode(node *MinHeapNode) *vizNode {
+	if node.isNil() {
+		return nil
+	}
+	return newVizBinaryNode(node.data,
+		heap.vizNode(node.left), heap.vizNode(node.right))
+}
+
+func (heap *MinHeapTree) vizForest() []*vizNode {
+	if heap.root.isNil() {
+		return nil
+	}
+	return []*vizNode{heap.vizNode(heap.root)}
+}
+
 func (heap *MinHeapTree) Viz() []byte {
-	text := "digraph structs {\n"
+	return heap.VizFormat(KeyString)
+}
 
-	heap.levelOrder(func(node *MinHeapNode) bool {
-		if node.data != nil {
-			curr := node.data.String()
-			text += fmt.Sprintf("\"%s\"; \n", curr)
-			if node.left != nil && node.left.data != nil {
-				text += fmt.Sprintf("\"%s\" -> \"%s\";\n",
-					curr, node.left.data.String())
-			}
-			if node.right != nil && node.right.data != nil {
-				text += fmt.Sprintf("\"%s\" -> \"%s\";\n",
-					curr, node.right.data.String())
-			}
-		}
-		return true
-	})
+// Return the heap as a DOT graph, nodes are labelled with the given formatter
+func (heap *MinHeapTree) VizFormat(format KeyFormatter) []byte {
+	return vizDot(heap.vizForest(), vizOptions{format: format})
+}
 
-	text += "}"
-	return []byte(text)
+// Draw the heap as a tree in the terminal
+func (heap *MinHeapTree) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, heap.vizForest(), opts)
 }
@@ -0,0 +1,257 @@
+package lib
+
+import (
+	"fmt"
+	"math/bits"
+
+	"golang.org/x/exp/slices"
+)
+
+/*
+MinMaxHeapArray is a double-ended heap with the array layout of MinHeapArray.
+The levels alternate: a key of an even level, starting with the root, is
+inferior or equal to all its descendants and a key of an odd level is
+superior or equal to all its descendants. The minimum is the root and the
+maximum one of its two children.
+*/
+type MinMaxHeapArray struct {
+	heapArray
+}
+
+func NewMinMaxHeapArray() *MinMaxHeapArray {
+	heap := &MinMaxHeapArray{}
+	heap.array = make([]*KeyInt, 0)
+	return heap
+}
+
+/*
+Checks if an index is on a min level, the root being on level 0.
+*/
+func (heap *MinMaxHeapArray) isMinLevel(i int) bool {
+	return bits.Len(uint(i+1))%2 == 1
+}
+
+/*
+Min returns the key with the minimum value without removing it, nil when the
+heap is empty.
+*/
+func (heap *MinMaxHeapArray) Min() *KeyInt {
+	return heap.top()
+}
+
+/*
+Max returns the key with the maximum value without removing it, nil when the
+heap is empty.
+*/
+func (heap *MinMaxHeapArray) Max() *KeyInt {
+	if len(heap.array) == 0 {
+		return nil
+	}
+	return heap.array[heap.maxIndex()]
+}
+
+/*
+Returns the index of the maximum, the root or one of its children; the heap
+must not be empty.
+*/
+func (heap *MinMaxHeapArray) maxIndex() int {
+	switch len(heap.array) {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	}
+	if heap.before(2, 1, false) {
+		return 2
+	}
+	return 1
+}
+
+/*
+SupprMin removes key with the minimum value.
+*/
+func (heap *MinMaxHeapArray) SupprMin() *KeyInt {
+	if len(heap.array) == 0 {
+		return nil
+	}
+	return heap.remove(0)
+}
+
+/*
+SupprMax removes key with the maximum value.
+*/
+func (heap *MinMaxHeapArray) SupprMax() *KeyInt {
+	if len(heap.array) == 0 {
+		return nil
+	}
+	return heap.remove(heap.maxIndex())
+}
+
+/*
+Remove the key at index i, replacing it by the last key which is then
+pushed down.
+*/
+func (heap *MinMaxHeapArray) remove(i int) *KeyInt {
+	last := len(heap.array) - 1
+	if i != last {
+		heap.swap(i, last)
+	}
+
+	key := heap.array[last]
+	heap.array = heap.array[:last]
+	heap.trace(TraceCut, key, nil)
+
+	if i < last {
+		heap.pushDown(i)
+	}
+	return key
+}
+
+/*
+pushDown
+
+Move the key at index i down until the heap property holds, on a min level;
+
+ 1. Find m, the smallest of the children and grandchildren of i.
+ 2. If m is a grandchild inferior to i, swap them; then if m is superior to
+    its parent, on a max level, swap them too and go on from m.
+ 3. If m is a child inferior to i, swap them and stop, a child has no
+    grandchild on a min level below it to check.
+
+Max levels are the same, with every comparison reversed.
+*/
+func (heap *MinMaxHeapArray) pushDown(i int) {
+	minLevel := heap.isMinLevel(i)
+	for {
+		child := heap.left(i)
+		if child >= len(heap.array) {
+			return
+		}
+
+		// smallest, or greatest, of the children and the grandchildren
+		m := child
+		for _, j := range []int{child + 1, heap.left(child), heap.left(child) + 1,
+			heap.left(child + 1), heap.left(child+1) + 1} {
+			if j < len(heap.array) && heap.before(j, m, minLevel) {
+				m = j
+			}
+		}
+
+		if !heap.before(m, i, minLevel) {
+			return
+		}
+		heap.swap(m, i)
+		if m <= child+1 {
+			return
+		}
+
+		parent := heap.parent(m)
+		if heap.before(parent, m, minLevel) {
+			heap.swap(m, parent)
+		}
+		i = m
+	}
+}
+
+/*
+Ajout
+
+Add a new element to the end of an array;
+
+ 1. Compare it with its parent, on the other kind of level, and swap them
+    if they are in the wrong order.
+ 2. Sift it up through its grandparents, on its own kind of level.
+*/
+func (heap *MinMaxHeapArray) Ajout(key *KeyInt) {
+	heap.array = append(heap.array, key)
+	heap.pushUp(len(heap.array) - 1)
+}
+
+func (heap *MinMaxHeapArray) pushUp(i int) {
+	if i == 0 {
+		return
+	}
+
+	minLevel := heap.isMinLevel(i)
+	parent := heap.parent(i)
+	if heap.before(parent, i, minLevel) {
+		heap.swap(i, parent)
+		i = parent
+		minLevel = !minLevel
+	}
+
+	// sift up through the grandparents
+	for i > 2 {
+		grandparent := heap.parent(heap.parent(i))
+		if !heap.before(i, grandparent, minLevel) {
+			return
+		}
+		heap.swap(i, grandparent)
+		i = grandparent
+	}
+}
+
+func (heap *MinMaxHeapArray) AjoutIteratif(keys []*KeyInt) {
+	for _, key := range keys {
+		heap.Ajout(key)
+	}
+}
+
+/*
+Construction adds every key then pushes down every subtree, from the last
+parent to the root, in O(n) like MinHeapArray.Construction.
+*/
+func (heap *MinMaxHeapArray) Construction(keys []*KeyInt) {
+	heap.array = append(heap.array, keys...)
+
+	for i := len(heap.array)/2 - 1; i >= 0; i-- {
+		heap.pushDown(i)
+	}
+}
+
+/**
+ * Union
+ */
+
+func HeapMinMaxUnion(lhs *MinMaxHeapArray, rhs *MinMaxHeapArray) *MinMaxHeapArray {
+	keys := slices.Clone(lhs.array)
+	keys = append(keys, rhs.array...)
+
+	heap := NewMinMaxHeapArray()
+	heap.Construction(keys)
+
+	return heap
+}
+
+/*
+Validate checks that every key is set, that a key of a min level is not
+superior to its max parent nor inferior to its min grandparent, and the
+reverse on max levels.
+*/
+func (heap *MinMaxHeapArray) Validate() error {
+	for i, key := range heap.array {
+		if key == nil {
+			return fmt.Errorf("nil key at index %d", i)
+		}
+		if i == 0 {
+			continue
+		}
+
+		minLevel := heap.isMinLevel(i)
+		parent := heap.parent(i)
+		if parentKey := heap.array[parent]; (minLevel && parentKey.Inf(key)) ||
+			(!minLevel && key.Inf(parentKey)) {
+			return fmt.Errorf("heap order broken at index %d: %v and its parent %v",
+				i, key, parentKey)
+		}
+		if parent == 0 {
+			continue
+		}
+		if grandparentKey := heap.array[heap.parent(parent)]; (minLevel && key.Inf(grandparentKey)) ||
+			(!minLevel && grandparentKey.Inf(key)) {
+			return fmt.Errorf("heap order broken at index %d: %v and its grandparent %v",
+				i, key, grandparentKey)
+		}
+	}
+	return nil
+}
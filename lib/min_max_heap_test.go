@@ -0,0 +1,117 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ lib.MinHeap = lib.NewMinMaxHeapArray()
+	_ lib.MaxHeap = lib.NewMinMaxHeapArray()
+	_ lib.MaxHeap = lib.NewMaxHeapArray()
+)
+
+func TestMinMaxHeapArray(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	sorted := sortedKeys(keys)
+
+	for _, build := range []string{"Ajout", "Construction"} {
+		heap := lib.NewMinMaxHeapArray()
+		if build == "Ajout" {
+			heap.AjoutIteratif(keys)
+		} else {
+			heap.Construction(keys)
+		}
+		assert.NoError(t, heap.Validate(), build)
+		assert.Equal(t, len(keys), heap.Len(), build)
+
+		// take the keys from both ends
+		lo, hi := 0, len(sorted)-1
+		for lo <= hi {
+			assert.Equal(t, sorted[lo], heap.Min(), build)
+			assert.Equal(t, sorted[hi], heap.Max(), build)
+			if (lo+hi)%3 == 0 {
+				assert.Equal(t, sorted[lo], heap.SupprMin(), build)
+				lo++
+			} else {
+				assert.Equal(t, sorted[hi], heap.SupprMax(), build)
+				hi--
+			}
+		}
+		assert.NoError(t, heap.Validate(), build)
+		assert.Nil(t, heap.Min(), build)
+		assert.Nil(t, heap.Max(), build)
+		assert.Nil(t, heap.SupprMin(), build)
+		assert.Nil(t, heap.SupprMax(), build)
+	}
+}
+
+func TestMinMaxHeapArrayRandomized(t *testing.T) {
+	for seed := int64(0); seed < 200; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		heap := lib.NewMinMaxHeapArray()
+		model := &heapModel{}
+
+		for step := 0; step < 100; step++ {
+			switch r.Intn(4) {
+			case 0, 1:
+				key := genHeapOpKey(r)
+				heap.Ajout(key)
+				model.add(key)
+			case 2:
+				expected := model.supprMin()
+				if err := checkSupprMin(step, expected, heap.SupprMin()); err != nil {
+					t.Fatalf("seed %d: %v", seed, err)
+				}
+			case 3:
+				var expected *lib.KeyInt
+				if len(model.keys) > 0 {
+					expected = model.keys[len(model.keys)-1]
+					model.keys = model.keys[:len(model.keys)-1]
+				}
+				actual := heap.SupprMax()
+				if (expected == nil) != (actual == nil) || (expected != nil && !expected.Eq(actual)) {
+					t.Fatalf("seed %d: step %d: SupprMax returned %v, expected %v",
+						seed, step, actual, expected)
+				}
+			}
+			if err := heap.Validate(); err != nil {
+				t.Fatalf("seed %d: step %d: %v", seed, step, err)
+			}
+		}
+	}
+}
+
+func TestMinMaxHeapArrayLevels(t *testing.T) {
+	heap := lib.NewMinMaxHeapArray()
+	heap.Construction(genKeys())
+	assert.NoError(t, heap.Validate())
+	assert.Equal(t, "[0-10, 0-50, 0-30, 0-40, 0-20]", heap.String())
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkSupprMax(b *testing.B) {
+	keys := getKeysFromFile(b, benchKeysPath(1, 120000))
+	b.Run("heapMax/cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			heap := lib.NewMaxHeapArray()
+			heap.Construction(keys)
+			for heap.SupprMax() != nil {
+			}
+		}
+	})
+	b.Run("heapMinMax/cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			heap := lib.NewMinMaxHeapArray()
+			heap.Construction(keys)
+			for heap.SupprMax() != nil {
+			}
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type traceable interface {
+	SetTracer(tracer lib.Tracer)
+}
+
+// Count the events of a single build of a new heap
+func countHeapOps(impl heapImpl, build func(heap lib.MinHeap)) lib.OpCounts {
+	counts := lib.OpCounts{}
+	heap := impl.new()
+	heap.(traceable).SetTracer(counts.Tracer())
+	build(heap)
+	return counts
+}
+
+func TestOpCounts(t *testing.T) {
+	// every key climbs up to the root
+	keys := genKeys()
+	expected := map[string]lib.OpCounts{
+		// the first key goes into the preallocated root
+		"heapTree":     {Compares: 6, Swaps: 6, Allocs: 4},
+		"heapArray":    {Compares: 6, Swaps: 6},
+		"heapBinomial": {Compares: 3, Links: 3, Allocs: 5},
+		// the keys climb through their grandparents, the first one of each
+		// level also swaps with its parent
+		"heapMinMax": {Compares: 6, Swaps: 4},
+	}
+	for _, impl := range heapImpls {
+		counts := countHeapOps(impl, func(heap lib.MinHeap) {
+			heap.AjoutIteratif([]*lib.KeyInt{keys[4], keys[3], keys[2], keys[1], keys[0]})
+		})
+		assert.Equal(t, expected[impl.name], counts, impl.name)
+	}
+
+	counts := lib.OpCounts{}
+	tree := lib.NewSearchTree()
+	tree.SetTracer(counts.Tracer())
+	for _, key := range []*lib.KeyInt{keys[2], keys[0], keys[4], keys[1], keys[3]} {
+		tree.Insert(key)
+	}
+	assert.Equal(t, lib.OpCounts{Compares: 6, Allocs: 4}, counts)
+
+	counts.Reset()
+	assert.Equal(t, keys[3], tree.Get(keys[3]))
+	assert.Equal(t, lib.OpCounts{Compares: 2}, counts)
+}
+
+// Construction does a linear number of comparisons, AjoutIteratif of
+// descending keys n log n: the comparisons per key must stay flat for the
+// first one and grow like log n for the second one
+func TestOpCountsComplexity(t *testing.T) {
+	sizes := []uint64{1 << 10, 1 << 13, 1 << 16}
+	// a key of the min-max heap only compares with every other ancestor
+	ajoutRatios := map[string]float64{"heapMinMax": 0.5}
+	for _, impl := range heapImpls {
+		for _, size := range sizes {
+			keys := genDescendingKeys(size)
+			n := float64(size)
+
+			construction := countHeapOps(impl, func(heap lib.MinHeap) {
+				heap.Construction(keys)
+			})
+			assert.Less(t, float64(construction.Compares)/n, 4.0,
+				"%s Construction of %d keys", impl.name, size)
+
+			if impl.name == "heapBinomial" {
+				continue
+			}
+			ajout := countHeapOps(impl, func(heap lib.MinHeap) {
+				heap.AjoutIteratif(keys)
+			})
+			expected, ok := ajoutRatios[impl.name]
+			if !ok {
+				expected = 1.0
+			}
+			ratio := float64(ajout.Compares) / (n * math.Log2(n))
+			assert.InDelta(t, expected, ratio, 0.2,
+				"%s AjoutIteratif of %d keys", impl.name, size)
+		}
+	}
+
+	// random keys into a search tree, about 1.39 n log n comparisons
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_120000.txt")
+	counts := lib.OpCounts{}
+	tree := lib.NewSearchTree()
+	tree.SetTracer(counts.Tracer())
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+	n := float64(len(keys))
+	assert.InDelta(t, 1.39, float64(counts.Compares)/(n*math.Log2(n)), 0.3)
+	assert.Equal(t, len(keys)-1, counts.Allocs)
+}
+
+/**
+ * Benchmarks
+ */
+
+// Report the counts of b.N builds of n keys
+func reportOpCounts(b *testing.B, counts lib.OpCounts, n int) {
+	ops := float64(b.N)
+	b.ReportMetric(float64(counts.Compares)/ops, "compares/op")
+	b.ReportMetric(float64(counts.Swaps)/ops, "swaps/op")
+	b.ReportMetric(float64(counts.Links)/ops, "links/op")
+	b.ReportMetric(float64(counts.Allocs)/ops, "nodes/op")
+	b.ReportMetric(float64(counts.Compares)/ops/float64(n), "compares/key")
+}
+
+func benchmarkHeapsOpCounts(
+	b *testing.B,
+	bench func(heap lib.MinHeap, keys []*lib.KeyInt),
+	withBinomial bool,
+) {
+	run := func(name string, keys []*lib.KeyInt) {
+		for _, impl := range heapImpls {
+			if impl.name == "heapBinomial" && !withBinomial {
+				continue
+			}
+			impl := impl
+			b.Run(impl.name+"/"+name, func(b *testing.B) {
+				counts := lib.OpCounts{}
+				for n := 0; n < b.N; n++ {
+					heap := impl.new()
+					heap.(traceable).SetTracer(counts.Tracer())
+					bench(heap, keys)
+				}
+				reportOpCounts(b, counts, len(keys))
+			})
+		}
+	}
+
+	dirEntries, err := os.ReadDir(*benchKeysDir)
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range dirEntries {
+		run(entry.Name(), getKeysFromFile(b, filepath.Join(*benchKeysDir, entry.Name())))
+	}
+
+	for _, nbKeys := range []uint64{250000, 500000, 1000000} {
+		keys := genDescendingKeys(nbKeys)
+		run("extra_jeu_nb_cles_"+strconv.FormatUint(nbKeys, 10), keys)
+	}
+}
+
+func BenchmarkOpsAjoutIteratif(b *testing.B) {
+	benchmarkHeapsOpCounts(b, func(heap lib.MinHeap, keys []*lib.KeyInt) {
+		heap.AjoutIteratif(keys)
+	}, false)
+}
+
+func BenchmarkOpsConstruction(b *testing.B) {
+	benchmarkHeapsOpCounts(b, func(heap lib.MinHeap, keys []*lib.KeyInt) {
+		heap.Construction(keys)
+	}, true)
+}
+
+func BenchmarkOpsSearchTree(b *testing.B) {
+	dirEntries, err := os.ReadDir(*benchKeysDir)
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range dirEntries {
+		keys := getKeysFromFile(b, filepath.Join(*benchKeysDir, entry.Name()))
+		b.Run("searchTree/"+entry.Name(), func(b *testing.B) {
+			counts := lib.OpCounts{}
+			for n := 0; n < b.N; n++ {
+				tree := lib.NewSearchTree()
+				tree.SetTracer(counts.Tracer())
+				for _, key := range keys {
+					tree.Insert(key)
+				}
+			}
+			reportOpCounts(b, counts, len(keys))
+		})
+	}
+}
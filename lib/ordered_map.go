@@ -0,0 +1,93 @@
+package lib
+
+/*
+OrderedMap maps keys to values and iterates over them in increasing order of
+key. It is a SearchTree whose nodes hold the value of their key, so it has
+the same costs: O(log n) operations on random keys, O(n) on sorted ones.
+Unlike SearchTree it holds each key once, Put replaces the value of an
+existing key.
+*/
+type OrderedMap[V any] struct {
+	tree *SearchTree
+}
+
+// Return the value of the node, the zero value of V when it holds nil
+func valueOf[V any](node *SearchTreeNode) V {
+	value, _ := node.value.(V)
+	return value
+}
+
+func NewOrderedMap[V any]() *OrderedMap[V] {
+	return &OrderedMap[V]{tree: NewSearchTree()}
+}
+
+// Register a function called on every compare, alloc and cut of the tree,
+// nil disables it
+func (m *OrderedMap[V]) SetTracer(tracer Tracer) {
+	m.tree.SetTracer(tracer)
+}
+
+// Number of keys of the map
+func (m *OrderedMap[V]) Len() int {
+	return m.tree.Len()
+}
+
+// Set the value of the key, replacing the previous one if any
+func (m *OrderedMap[V]) Put(key *KeyInt, value V) {
+	node, _ := m.tree.insertUnique(key)
+	node.value = value
+}
+
+// Return the value of the key, ok is false when the key is missing
+func (m *OrderedMap[V]) Get(key *KeyInt) (value V, ok bool) {
+	node := m.tree.findNode(key)
+	if node == nil {
+		return value, false
+	}
+	return valueOf[V](node), true
+}
+
+// Return the value of the key when present, loaded is true; otherwise add
+// the key with the given value and return it, in a single search
+func (m *OrderedMap[V]) GetOrInsert(key *KeyInt, value V) (actual V, loaded bool) {
+	node, inserted := m.tree.insertUnique(key)
+	if inserted {
+		node.value = value
+		return value, false
+	}
+	return valueOf[V](node), true
+}
+
+// Remove the key and its value, return whether the key was present
+func (m *OrderedMap[V]) Delete(key *KeyInt) bool {
+	return m.tree.Delete(key)
+}
+
+// Call yield on every key and its value in increasing order of key until it
+// returns false
+func (m *OrderedMap[V]) Ascend(yield func(key *KeyInt, value V) bool) {
+	m.Range(nil, nil, yield)
+}
+
+// Call yield on every key within [lower, upper) and its value in increasing
+// order of key until it returns false, nil bounds are unbounded
+func (m *OrderedMap[V]) Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt, value V) bool) {
+	m.tree.rangeNodes(lower, upper, func(node *SearchTreeNode) bool {
+		return yield(node.data, valueOf[V](node))
+	})
+}
+
+// Return the keys in increasing order
+func (m *OrderedMap[V]) Keys() []*KeyInt {
+	keys := make([]*KeyInt, 0, m.Len())
+	m.tree.Ascend(func(key *KeyInt) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Check the order of the tree
+func (m *OrderedMap[V]) Validate() error {
+	return m.tree.Validate()
+}
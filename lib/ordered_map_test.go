@@ -0,0 +1,143 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMap(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_5000.txt")
+	m := lib.NewOrderedMap[int]()
+	model := make(map[lib.KeyInt]int)
+	r := rand.New(rand.NewSource(1))
+
+	for step := 0; step < 20000; step++ {
+		key := keys[r.Intn(len(keys))]
+		switch r.Intn(4) {
+		case 0:
+			m.Put(key, step)
+			model[*key] = step
+		case 1:
+			expected, expectedOk := model[*key]
+			actual, ok := m.Get(key)
+			assert.Equal(t, expectedOk, ok)
+			assert.Equal(t, expected, actual)
+		case 2:
+			_, expected := model[*key]
+			delete(model, *key)
+			assert.Equal(t, expected, m.Delete(key))
+		case 3:
+			expected, expectedLoaded := model[*key]
+			if !expectedLoaded {
+				expected = step
+				model[*key] = step
+			}
+			actual, loaded := m.GetOrInsert(key, step)
+			assert.Equal(t, expectedLoaded, loaded)
+			assert.Equal(t, expected, actual)
+		}
+		assert.Equal(t, len(model), m.Len())
+	}
+	assert.NoError(t, m.Validate())
+
+	// every key once, in increasing order
+	ascended := make([]*lib.KeyInt, 0)
+	m.Ascend(func(key *lib.KeyInt, value int) bool {
+		assert.Equal(t, model[*key], value)
+		ascended = append(ascended, key)
+		return true
+	})
+	assert.Equal(t, m.Keys(), ascended)
+	assert.Equal(t, len(model), len(ascended))
+	for i := 1; i < len(ascended); i++ {
+		assert.True(t, ascended[i-1].Inf(ascended[i]))
+	}
+}
+
+func TestOrderedMapShakespeare(t *testing.T) {
+	// hash to word index, no side map needed
+	words := lib.NewOrderedMap[string]()
+	getShakespeareWords(func(word string, _ string) {
+		words.Put(lib.NewKeyIntFromBytes(lib.MD5([]byte(word))), word)
+	})
+	assert.Equal(t, 23086, words.Len())
+
+	word, ok := words.Get(lib.NewKeyIntFromBytes(lib.MD5([]byte("hamlet"))))
+	assert.True(t, ok)
+	assert.Equal(t, "hamlet", word)
+	_, ok = words.Get(lib.NewKeyIntFromBytes(lib.MD5([]byte("hamletto"))))
+	assert.False(t, ok)
+
+	words.Ascend(func(key *lib.KeyInt, word string) bool {
+		return assert.True(t, key.Eq(lib.NewKeyIntFromBytes(lib.MD5([]byte(word)))), word)
+	})
+}
+
+func TestOrderedMapRange(t *testing.T) {
+	keys := genKeys()
+	m := lib.NewOrderedMap[string]()
+	for i, key := range keys {
+		m.Put(key, string(rune('a'+i)))
+	}
+	m.Put(keys[1], "B")
+
+	values := ""
+	m.Range(keys[1], keys[4], func(_ *lib.KeyInt, value string) bool {
+		values += value
+		return true
+	})
+	assert.Equal(t, "Bcd", values)
+	assert.Equal(t, 5, m.Len())
+}
+
+func TestOrderedMapNilValue(t *testing.T) {
+	m := lib.NewOrderedMap[error]()
+	key := genKeys()[0]
+	m.Put(key, nil)
+	err, ok := m.Get(key)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+
+	err, loaded := m.GetOrInsert(key, errors.New("not stored"))
+	assert.True(t, loaded)
+	assert.Nil(t, err)
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkOrderedMapWords(b *testing.B) {
+	words := getShakespeareUniqueWords()
+	keys := make([]*lib.KeyInt, 0, len(words))
+	for _, word := range words {
+		keys = append(keys, lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	}
+
+	b.Run("orderedMap/cles_23086", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			m := lib.NewOrderedMap[string]()
+			for i, key := range keys {
+				m.Put(key, words[i])
+			}
+			for _, key := range keys {
+				m.Get(key)
+			}
+		}
+	})
+	b.Run("goMap/cles_23086", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			m := make(map[string]string)
+			for i, key := range keys {
+				m[key.String()] = words[i]
+			}
+			for _, key := range keys {
+				_ = m[key.String()]
+			}
+		}
+	})
+}
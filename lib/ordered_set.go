@@ -0,0 +1,18 @@
+package lib
+
+// OrderedSet holds keys in increasing order, equal keys included, with
+// SearchTree, Treap, SplayTree and BTree as implementations. The keys
+// returned by Get, Ascend and Range stay valid after any later change of the
+// set; they are the inserted pointers except in a BTree, which stores the
+// keys themselves and returns copies of them.
+type OrderedSet interface {
+	Insert(key *KeyInt)
+	Get(key *KeyInt) *KeyInt
+	Delete(key *KeyInt) bool
+	Len() int
+	Ascend(yield func(key *KeyInt) bool)
+	Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool)
+	MaxLevel() int
+	Viz() []byte
+	Validate() error
+}
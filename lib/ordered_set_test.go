@@ -0,0 +1,184 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var orderedSets = map[string]func() lib.OrderedSet{
+	"searchTree": func() lib.OrderedSet { return lib.NewSearchTree() },
+	"treap":      func() lib.OrderedSet { return lib.NewTreap() },
+	"splayTree":  func() lib.OrderedSet { return lib.NewSplayTree() },
+	"bTree":      func() lib.OrderedSet { return lib.NewBTree(2) },
+}
+
+// Return the keys of the set in increasing order
+func setKeys(set lib.OrderedSet) []*lib.KeyInt {
+	keys := make([]*lib.KeyInt, 0, set.Len())
+	set.Ascend(func(key *lib.KeyInt) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func TestOrderedSetShakespeareUniqueWords(t *testing.T) {
+	for name, newSet := range orderedSets {
+		wordSet := newSet()
+		words := make([]string, 0)
+		totalWords := 0
+
+		getShakespeareWords(func(word string, _ string) {
+			hash := lib.MD5([]byte(word))
+			key := lib.NewKeyIntFromBytes(hash)
+			totalWords++
+
+			// not already here
+			if wordSet.Get(key) == nil {
+				wordSet.Insert(key)
+				words = append(words, word)
+			}
+		})
+
+		assert.Equal(t, 23086, len(words), name)
+		assert.Equal(t, 905534, totalWords, name)
+		assert.Equal(t, 23086, wordSet.Len(), name)
+		assert.NoError(t, wordSet.Validate(), name)
+		t.Logf("%s: max level %d", name, wordSet.MaxLevel())
+	}
+}
+
+func TestOrderedSetRandomized(t *testing.T) {
+	for name, newSet := range orderedSets {
+		for seed := int64(0); seed < 20; seed++ {
+			r := rand.New(rand.NewSource(seed))
+			set := newSet()
+			model := &heapModel{}
+
+			for step := 0; step < 300; step++ {
+				key := genHeapOpKey(r)
+				msg := fmt.Sprintf("%s: seed %d step %d", name, seed, step)
+				switch r.Intn(4) {
+				case 0, 1:
+					set.Insert(key)
+					model.add(key)
+				case 2:
+					expected := lowerBound(model.keys, key)
+					found := expected < len(model.keys) && model.keys[expected].Eq(key)
+					if found {
+						model.keys = append(model.keys[:expected], model.keys[expected+1:]...)
+					}
+					assert.Equal(t, found, set.Delete(key), msg)
+				case 3:
+					i := lowerBound(model.keys, key)
+					if i < len(model.keys) && model.keys[i].Eq(key) {
+						assert.True(t, key.Eq(set.Get(key)), msg)
+					} else {
+						assert.Nil(t, set.Get(key), msg)
+					}
+				}
+				if !assert.NoError(t, set.Validate(), msg) {
+					return
+				}
+				assert.Equal(t, len(model.keys), set.Len(), msg)
+			}
+			assertEqualKeys(t, model.keys, setKeys(set), name)
+
+			lower, upper := genHeapOpKey(r), genHeapOpKey(r)
+			expected := make([]*lib.KeyInt, 0)
+			for _, key := range model.keys {
+				if !key.Inf(lower) && key.Inf(upper) {
+					expected = append(expected, key)
+				}
+			}
+			ranged := make([]*lib.KeyInt, 0)
+			set.Range(lower, upper, func(key *lib.KeyInt) bool {
+				ranged = append(ranged, key)
+				return true
+			})
+			assertEqualKeys(t, expected, ranged, name)
+		}
+	}
+}
+
+func TestOrderedSetSorted(t *testing.T) {
+	keys := sortedKeys(getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_5000.txt"))
+
+	// sorted keys make a path of the search tree, not of the treap
+	treap := lib.NewTreap()
+	for _, key := range keys {
+		treap.Insert(key)
+	}
+	assert.NoError(t, treap.Validate())
+	assert.Less(t, treap.MaxLevel(), 4*bits.Len(uint(len(keys))))
+
+	// and of the splay tree until it is searched
+	splay := lib.NewSplayTree()
+	for _, key := range keys {
+		splay.Insert(key)
+	}
+	assert.Equal(t, len(keys), splay.MaxLevel())
+	splay.Get(keys[0])
+	assert.Less(t, splay.MaxLevel(), len(keys)/2+2)
+	assert.Equal(t, keys[0], setKeys(splay)[0])
+	assert.NoError(t, splay.Validate())
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		assert.True(t, splay.Delete(keys[i]))
+		assert.True(t, treap.Delete(keys[i]))
+	}
+	assert.Equal(t, 0, splay.Len())
+	assert.Equal(t, 0, treap.Len())
+	assert.Equal(t, 0, treap.MaxLevel())
+	assert.False(t, splay.Delete(keys[0]))
+}
+
+/**
+ * Benchmarks
+ */
+
+// Run the search for unique words on the keys of every Shakespeare word,
+// reporting the height of the final set
+func BenchmarkOrderedSetWords(b *testing.B) {
+	keys := make([]*lib.KeyInt, 0)
+	getShakespeareWords(func(word string, _ string) {
+		keys = append(keys, lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	})
+	// a search tree of sorted keys is a path, its build is quadratic
+	sorted := sortedKeys(getKeysFromFile(b, benchKeysPath(1, 5000)))
+
+	for _, name := range []string{"searchTree", "treap", "splayTree"} {
+		newSet := orderedSets[name]
+		b.Run(name+"/words_"+strconv.Itoa(len(keys)), func(b *testing.B) {
+			var set lib.OrderedSet
+			for n := 0; n < b.N; n++ {
+				set = newSet()
+				for _, key := range keys {
+					if set.Get(key) == nil {
+						set.Insert(key)
+					}
+				}
+			}
+			b.ReportMetric(float64(set.MaxLevel()), "maxlevel")
+		})
+		b.Run(name+"/sorted_cles_"+strconv.Itoa(len(sorted)), func(b *testing.B) {
+			var set lib.OrderedSet
+			for n := 0; n < b.N; n++ {
+				set = newSet()
+				for _, key := range sorted {
+					set.Insert(key)
+				}
+				for _, key := range sorted {
+					set.Get(key)
+				}
+			}
+			b.ReportMetric(float64(set.MaxLevel()), "maxlevel")
+		})
+	}
+}
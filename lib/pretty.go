@@ -0,0 +1,107 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// PrettyOptions tunes the terminal drawing of the Pretty methods
+type PrettyOptions struct {
+	// Label of the keys, KeyString when nil
+	Format KeyFormatter
+	// Number of levels drawn below the roots, deeper nodes are only counted,
+	// 0 draws everything
+	MaxDepth int
+	// Labels longer than this are cut and end with an ellipsis, 0 keeps them whole
+	MaxKeyLen int
+	// Draw with plain ASCII characters instead of box-drawing ones
+	ASCII bool
+}
+
+type prettyGlyphs struct {
+	branch, last, pipe, space, missing, ellipsis string
+}
+
+var (
+	prettyUnicode = prettyGlyphs{"├── ", "└── ", "│   ", "    ", "∅", "…"}
+	prettyASCII   = prettyGlyphs{"|-- ", "`-- ", "|   ", "    ", "-", "..."}
+)
+
+func (opts *PrettyOptions) glyphs() prettyGlyphs {
+	if opts.ASCII {
+		return prettyASCII
+	}
+	return prettyUnicode
+}
+
+func (opts *PrettyOptions) label(node *vizNode) string {
+	format := opts.Format
+	if format == nil {
+		format = KeyString
+	}
+
+	label := format(node.key)
+	if opts.MaxKeyLen > 0 && utf8.RuneCountInString(label) > opts.MaxKeyLen {
+		runes := []rune(label)
+		label = string(runes[:opts.MaxKeyLen]) + opts.glyphs().ellipsis
+	}
+	if node.note != "" {
+		label += " (" + node.note + ")"
+	}
+	return label
+}
+
+// Count the nodes of a subtree, the root included
+func (node *vizNode) count() int {
+	count := 1
+	for _, child := range node.children {
+		if child != nil {
+			count += child.count()
+		}
+	}
+	return count
+}
+
+// Draw a forest as an indented tree, one node per line
+func prettyForest(w io.Writer, forest []*vizNode, opts PrettyOptions) error {
+	out := bufio.NewWriter(w)
+	glyphs := opts.glyphs()
+
+	var writeChildren func(node *vizNode, prefix string, depth int)
+	writeChildren = func(node *vizNode, prefix string, depth int) {
+		if !node.hasChildren() {
+			return
+		}
+
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			fmt.Fprintf(out, "%s%s%s %d more\n", prefix, glyphs.last,
+				glyphs.ellipsis, node.count()-1)
+			return
+		}
+
+		for i, child := range node.children {
+			connector, indent := glyphs.branch, glyphs.pipe
+			if i == len(node.children)-1 {
+				connector, indent = glyphs.last, glyphs.space
+			}
+			if child == nil {
+				fmt.Fprintf(out, "%s%s%s\n", prefix, connector, glyphs.missing)
+				continue
+			}
+			fmt.Fprintf(out, "%s%s%s\n", prefix, connector, opts.label(child))
+			writeChildren(child, prefix+indent, depth+1)
+		}
+	}
+
+	if len(forest) == 0 {
+		fmt.Fprintln(out, "(empty)")
+	}
+	for _, root := range forest {
+		fmt.Fprintln(out, opts.label(root))
+		writeChildren(root, "", 0)
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,73 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyHeaps(t *testing.T) {
+	heapArray := lib.NewMinHeapArray()
+	heapArray.Construction(genKeys())
+	heapTree := lib.NewMinHeapTree()
+	heapTree.Construction(genKeys())
+
+	expected := "0-10\n" +
+		"├── 0-20\n" +
+		"│   ├── 0-40\n" +
+		"│   └── 0-50\n" +
+		"└── 0-30\n"
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, heapArray.Pretty(buf, lib.PrettyOptions{}))
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, heapTree.Pretty(buf, lib.PrettyOptions{}))
+	assert.Equal(t, expected, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, heapArray.Pretty(buf, lib.PrettyOptions{MaxDepth: 1, ASCII: true}))
+	assert.Equal(t, "0-10\n"+
+		"|-- 0-20\n"+
+		"|   `-- ... 2 more\n"+
+		"`-- 0-30\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, lib.NewMinHeapArray().Pretty(buf, lib.PrettyOptions{}))
+	assert.Equal(t, "(empty)\n", buf.String())
+}
+
+func TestPrettyBinomial(t *testing.T) {
+	heap := lib.NewMinHeapBinomial()
+	heap.Construction(genKeys())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, heap.Pretty(buf, lib.PrettyOptions{
+		Format:    lib.KeyHex,
+		MaxKeyLen: 6,
+	}))
+	assert.Equal(t, "0x0000… (B0)\n"+
+		"0x0000… (B2)\n"+
+		"├── 0x0000…\n"+
+		"└── 0x0000…\n"+
+		"    └── 0x0000…\n", buf.String())
+}
+
+func TestPrettySearchTree(t *testing.T) {
+	keys := genKeys()
+	tree := lib.NewSearchTree()
+	tree.Insert(keys[1])
+	tree.Insert(keys[3])
+	tree.Insert(keys[4])
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, tree.Pretty(buf, lib.PrettyOptions{}))
+	assert.Equal(t, "0-20\n"+
+		"├── ∅\n"+
+		"└── 0-40\n"+
+		"    ├── ∅\n"+
+		"    └── 0-50\n", buf.String())
+}
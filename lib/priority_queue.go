@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+/*
+PriorityQueue holds keys with a value each, on top of any MinHeap such as
+MinHeapArray or MinHeapBinomial. The heap holds the key of an entry which
+carries the value, so the same key, or the same pointer, can be added several
+times with different values. The heap must give back the pointers it was
+given, as every MinHeap of this package does.
+*/
+type PriorityQueue[V any] struct {
+	heap MinHeap
+	size int
+}
+
+// A copy of the key with its value, the heap holds a pointer to the key
+// which is also a pointer to the entry
+type queueEntry[V any] struct {
+	key   KeyInt
+	value V
+}
+
+// Return the entry of a key of the heap; the key is the first field of the
+// entry so they share the same address
+func entryOf[V any](key *KeyInt) *queueEntry[V] {
+	return (*queueEntry[V])(unsafe.Pointer(key))
+}
+
+// Return a queue storing its keys in the given heap, which must be empty
+// and not be used directly anymore
+func NewPriorityQueue[V any](heap MinHeap) *PriorityQueue[V] {
+	return &PriorityQueue[V]{heap: heap}
+}
+
+// Number of values of the queue
+func (queue *PriorityQueue[V]) Len() int {
+	return queue.size
+}
+
+// Add a value with the given priority, the smallest key comes out first
+func (queue *PriorityQueue[V]) Ajout(key *KeyInt, value V) {
+	entry := &queueEntry[V]{*key, value}
+	queue.heap.Ajout(&entry.key)
+	queue.size++
+}
+
+// Add every value at once with the Construction of the heap, the values
+// having the keys of the same index
+func (queue *PriorityQueue[V]) Construction(keys []*KeyInt, values []V) {
+	if len(keys) != len(values) {
+		panic(fmt.Sprintf("%d keys for %d values", len(keys), len(values)))
+	}
+	entries := make([]queueEntry[V], len(keys))
+	pointers := make([]*KeyInt, len(keys))
+	for i, key := range keys {
+		entries[i] = queueEntry[V]{*key, values[i]}
+		pointers[i] = &entries[i].key
+	}
+	queue.heap.Construction(pointers)
+	queue.size += len(keys)
+}
+
+// Remove the value of the smallest key and return both, ok is false when
+// the queue is empty
+func (queue *PriorityQueue[V]) SupprMin() (key *KeyInt, value V, ok bool) {
+	key = queue.heap.SupprMin()
+	if key == nil {
+		return nil, value, false
+	}
+	queue.size--
+	entry := entryOf[V](key)
+	value = entry.value
+	// the returned key keeps its entry alive, and the entries of a
+	// Construction share a single array, so do not keep the value alive
+	var zero V
+	entry.value = zero
+	return key, value, true
+}
@@ -0,0 +1,145 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var queueHeaps = map[string]func() lib.MinHeap{
+	"heapArray":    func() lib.MinHeap { return lib.NewMinHeapArray() },
+	"heapTree":     func() lib.MinHeap { return lib.NewMinHeapTree() },
+	"heapBinomial": func() lib.MinHeap { return lib.NewMinHeapBinomial() },
+}
+
+func getShakespeareUniqueWords() []string {
+	words := make([]string, 0)
+	for _, fileWords := range getShakespeareUniqueWordsFiles() {
+		words = append(words, fileWords...)
+	}
+	return words
+}
+
+func TestPriorityQueueShakespeare(t *testing.T) {
+	words := getShakespeareUniqueWords()
+	keys := make([]*lib.KeyInt, 0, len(words))
+	for _, word := range words {
+		keys = append(keys, lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	}
+
+	for name, newHeap := range queueHeaps {
+		queue := lib.NewPriorityQueue[string](newHeap())
+		queue.Construction(keys, words)
+		assert.Equal(t, len(words), queue.Len(), name)
+
+		// the words come out by increasing hash, each with its own hash
+		var last *lib.KeyInt
+		for i := 0; i < len(words); i++ {
+			key, word, ok := queue.SupprMin()
+			if !assert.True(t, ok, name) {
+				break
+			}
+			if !assert.True(t, key.Eq(lib.NewKeyIntFromBytes(lib.MD5([]byte(word)))), "%s: %s", name, word) {
+				break
+			}
+			if last != nil && !assert.False(t, key.Inf(last), name) {
+				break
+			}
+			last = key
+		}
+		_, word, ok := queue.SupprMin()
+		assert.False(t, ok, name)
+		assert.Equal(t, "", word, name)
+		assert.Equal(t, 0, queue.Len(), name)
+	}
+}
+
+type job struct {
+	name     string
+	priority int
+}
+
+func TestPriorityQueueSamePointer(t *testing.T) {
+	for name, newHeap := range queueHeaps {
+		queue := lib.NewPriorityQueue[*job](newHeap())
+		key := lib.NewKeyInt(0, 2)
+		queue.Ajout(key, &job{"first", 2})
+		queue.Ajout(key, &job{"second", 2})
+		queue.Ajout(lib.NewKeyInt(0, 1), &job{"urgent", 1})
+
+		_, urgent, _ := queue.SupprMin()
+		assert.Equal(t, "urgent", urgent.name, name)
+		names := make([]string, 0)
+		for queue.Len() > 0 {
+			returned, job, _ := queue.SupprMin()
+			assert.True(t, returned.Eq(key), name)
+			names = append(names, job.name)
+		}
+		assert.ElementsMatch(t, []string{"first", "second"}, names, name)
+	}
+}
+
+func TestPriorityQueueInterleaved(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	for name, newHeap := range queueHeaps {
+		queue := lib.NewPriorityQueue[int](newHeap())
+		model := &heapModel{}
+		indexes := make(map[lib.KeyInt]int)
+
+		for i, key := range keys {
+			queue.Ajout(key, i)
+			model.add(key)
+			indexes[*key] = i
+			if i%3 == 2 {
+				key, index, ok := queue.SupprMin()
+				assert.True(t, ok, name)
+				assert.True(t, model.supprMin().Eq(key), name)
+				assert.Equal(t, indexes[*key], index, name)
+			}
+		}
+		assert.Equal(t, len(model.keys), queue.Len(), name)
+	}
+}
+
+func TestPriorityQueueConstructionMismatch(t *testing.T) {
+	queue := lib.NewPriorityQueue[string](lib.NewMinHeapArray())
+	assert.PanicsWithValue(t, "2 keys for 1 values", func() {
+		queue.Construction(genKeys()[:2], []string{"a"})
+	})
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkPriorityQueueWords(b *testing.B) {
+	words := getShakespeareUniqueWords()
+	keys := make([]*lib.KeyInt, 0, len(words))
+	for _, word := range words {
+		keys = append(keys, lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	}
+	name := "cles_" + strconv.Itoa(len(keys))
+
+	for _, heapName := range []string{"heapArray", "heapBinomial"} {
+		newHeap := queueHeaps[heapName]
+		b.Run(heapName+"/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				heap := newHeap()
+				heap.Construction(keys)
+				for heap.SupprMin() != nil {
+				}
+			}
+		})
+		b.Run(heapName+"Queue/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				queue := lib.NewPriorityQueue[string](newHeap())
+				queue.Construction(keys, words)
+				for queue.Len() > 0 {
+					queue.SupprMin()
+				}
+			}
+		})
+	}
+}
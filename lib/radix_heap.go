@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+)
+
+// Number of buckets of a radix heap, one per bit of a key and one for the
+// keys equal to the last minimum
+const radixBuckets = 128 + 1
+
+/*
+RadixHeap is a monotone priority queue: a key must never be inferior to the
+last key returned by SupprMin, the zero key before the first one. It
+exploits that precondition to sort the keys by their highest bit differing
+from the last minimum instead of comparing them with each other.
+
+Bucket 0 holds the keys equal to the last minimum and bucket i the keys whose
+highest bit differing from it is bit i-1. A SupprMin on an empty bucket 0
+empties the first non-empty bucket: its minimum becomes the last minimum and
+its keys all move to lower buckets, so every key moves at most 128 times.
+
+Breaking the precondition silently returns the keys in the wrong order,
+builds with -tags debug panic instead.
+*/
+type RadixHeap struct {
+	buckets [radixBuckets][]*KeyInt
+	last    KeyInt
+	size    int
+	tracer  Tracer
+}
+
+func NewRadixHeap() *RadixHeap {
+	return &RadixHeap{}
+}
+
+/*
+SetTracer registers a function called on every compare and cut, nil disables it.
+*/
+func (heap *RadixHeap) SetTracer(tracer Tracer) {
+	heap.tracer = tracer
+}
+
+/*
+Reports an event to the tracer, if any.
+*/
+func (heap *RadixHeap) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt) {
+	if heap.tracer != nil {
+		heap.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: heap})
+	}
+}
+
+/*
+Returns the bucket of a key, the bit length of its XOR with the last minimum.
+*/
+func (heap *RadixHeap) bucket(key *KeyInt) int {
+	return 128 - key.Xor(&heap.last).LeadingZeros()
+}
+
+/*
+Len returns the number of keys of the heap.
+*/
+func (heap *RadixHeap) Len() int {
+	return heap.size
+}
+
+/*
+Ajout adds a key to its bucket, in O(1); the key must not be inferior to the
+last minimum.
+*/
+func (heap *RadixHeap) Ajout(key *KeyInt) {
+	if debug && key.Inf(&heap.last) {
+		panic(fmt.Sprintf("radix heap: key %v is inferior to the last minimum %v", key, &heap.last))
+	}
+	i := heap.bucket(key)
+	heap.buckets[i] = append(heap.buckets[i], key)
+	heap.size++
+}
+
+func (heap *RadixHeap) AjoutIteratif(keys []*KeyInt) {
+	for _, key := range keys {
+		heap.Ajout(key)
+	}
+}
+
+func (heap *RadixHeap) Construction(keys []*KeyInt) {
+	heap.AjoutIteratif(keys)
+}
+
+/*
+SupprMin
+
+Remove the key with the minimum value, in O(log U) amortized with U the
+range of the keys;
+
+ 1. If bucket 0 is empty, find the first non-empty bucket and its minimum,
+    which becomes the last minimum.
+ 2. Move every key of that bucket to its new bucket, always a lower one as
+    the keys share more high bits with the new minimum.
+ 3. Return a key of bucket 0.
+*/
+func (heap *RadixHeap) SupprMin() *KeyInt {
+	if heap.size == 0 {
+		return nil
+	}
+
+	if len(heap.buckets[0]) == 0 {
+		i := 1
+		for len(heap.buckets[i]) == 0 {
+			i++
+		}
+
+		bucket := heap.buckets[i]
+		minKey := bucket[0]
+		for _, key := range bucket[1:] {
+			heap.trace(TraceCompare, key, minKey)
+			if key.Inf(minKey) {
+				minKey = key
+			}
+		}
+
+		heap.last = *minKey
+		for _, key := range bucket {
+			j := heap.bucket(key)
+			heap.buckets[j] = append(heap.buckets[j], key)
+		}
+		heap.buckets[i] = bucket[:0]
+	}
+
+	bucket := heap.buckets[0]
+	key := bucket[len(bucket)-1]
+	heap.buckets[0] = bucket[:len(bucket)-1]
+	heap.size--
+	heap.trace(TraceCut, key, nil)
+	return key
+}
+
+/*
+Validate checks that every key is set, not inferior to the last minimum and
+in the bucket of its highest bit differing from it.
+*/
+func (heap *RadixHeap) Validate() error {
+	size := 0
+	for i, bucket := range heap.buckets {
+		for _, key := range bucket {
+			if key == nil {
+				return fmt.Errorf("nil key in bucket %d", i)
+			}
+			if key.Inf(&heap.last) {
+				return fmt.Errorf("key %v is inferior to the last minimum %v", key, &heap.last)
+			}
+			if j := heap.bucket(key); j != i {
+				return fmt.Errorf("key %v in bucket %d instead of %d", key, i, j)
+			}
+		}
+		size += len(bucket)
+	}
+	if size != heap.size {
+		return fmt.Errorf("%d keys in the buckets, the heap counts %d", size, heap.size)
+	}
+	return nil
+}
+
+func (heap *RadixHeap) String() string {
+	text := "["
+	for _, bucket := range heap.buckets {
+		for _, key := range bucket {
+			if text != "[" {
+				text += ", "
+			}
+			text += key.String()
+		}
+	}
+	return text + "]"
+}
+
+// One tree per non-empty bucket, its first key as root and the others as
+// children
+func (heap *RadixHeap) vizForest() []*vizNode {
+	forest := make([]*vizNode, 0)
+	for i, bucket := range heap.buckets {
+		if len(bucket) == 0 {
+			continue
+		}
+		node := &vizNode{key: bucket[0], note: fmt.Sprintf("b%d", i)}
+		for _, key := range bucket[1:] {
+			node.children = append(node.children, &vizNode{key: key})
+		}
+		forest = append(forest, node)
+	}
+	return forest
+}
+
+func (heap *RadixHeap) Viz() []byte {
+	return heap.VizFormat(KeyString)
+}
+
+/*
+VizFormat returns the heap as a DOT graph, labelling nodes with the given formatter.
+*/
+func (heap *RadixHeap) VizFormat(format KeyFormatter) []byte {
+	return vizDot(heap.vizForest(), vizOptions{format: format})
+}
+
+/*
+Pretty draws the buckets as trees in the terminal.
+*/
+func (heap *RadixHeap) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, heap.vizForest(), opts)
+}
@@ -0,0 +1,22 @@
+//go:build debug
+
+package lib_test
+
+import (
+	"arithmos/lib"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -tags debug ./lib checks the monotonicity of the radix heap
+func TestRadixHeapNotMonotone(t *testing.T) {
+	heap := lib.NewRadixHeap()
+	heap.AjoutIteratif([]*lib.KeyInt{lib.NewKeyInt(0, 10), lib.NewKeyInt(0, 20)})
+	assert.Equal(t, lib.NewKeyInt(0, 10), heap.SupprMin())
+
+	heap.Ajout(lib.NewKeyInt(0, 10))
+	assert.PanicsWithValue(t, "radix heap: key 0-5 is inferior to the last minimum 0-10", func() {
+		heap.Ajout(lib.NewKeyInt(0, 5))
+	})
+}
@@ -0,0 +1,123 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ lib.MinHeap = lib.NewRadixHeap()
+
+func TestRadixHeap(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	sorted := sortedKeys(keys)
+
+	for _, build := range []string{"Ajout", "Construction"} {
+		heap := lib.NewRadixHeap()
+		if build == "Ajout" {
+			heap.AjoutIteratif(keys)
+		} else {
+			heap.Construction(keys)
+		}
+		assert.NoError(t, heap.Validate(), build)
+		assert.Equal(t, len(keys), heap.Len(), build)
+
+		drained := make([]*lib.KeyInt, 0, len(keys))
+		for key := heap.SupprMin(); key != nil; key = heap.SupprMin() {
+			drained = append(drained, key)
+		}
+		assertEqualKeys(t, sorted, drained, build)
+		assert.NoError(t, heap.Validate(), build)
+		assert.Equal(t, 0, heap.Len(), build)
+	}
+}
+
+// Event queue of a simulation, the time of each key is kept by pointer as
+// the keys can't be read back
+type eventQueue struct {
+	heap  lib.MinHeap
+	times map[*lib.KeyInt]uint64
+}
+
+func (queue *eventQueue) schedule(time uint64) {
+	key := lib.NewKeyInt(0, time)
+	queue.times[key] = time
+	queue.heap.Ajout(key)
+}
+
+// Pop the next event and schedule a later one, the popped keys never
+// decrease so the radix heap must agree with an array heap
+func simulateEvents(heap lib.MinHeap, r *rand.Rand, nbEvents int, maxDelay int64) []*lib.KeyInt {
+	queue := &eventQueue{heap, make(map[*lib.KeyInt]uint64)}
+	for i := 0; i < 100; i++ {
+		queue.schedule(uint64(r.Int63n(maxDelay)))
+	}
+	popped := make([]*lib.KeyInt, 0, nbEvents)
+	for i := 0; i < nbEvents; i++ {
+		key := heap.SupprMin()
+		popped = append(popped, key)
+		// a delay of 0 schedules an event equal to the last minimum
+		queue.schedule(queue.times[key] + uint64(r.Int63n(maxDelay)))
+		delete(queue.times, key)
+	}
+	return popped
+}
+
+func TestRadixHeapMonotone(t *testing.T) {
+	for _, maxDelay := range []int64{1, 3, 1000, 1 << 40} {
+		radix := lib.NewRadixHeap()
+		expected := simulateEvents(lib.NewMinHeapArray(), rand.New(rand.NewSource(1)), 2000, maxDelay)
+		actual := simulateEvents(radix, rand.New(rand.NewSource(1)), 2000, maxDelay)
+		assertEqualKeys(t, expected, actual, "delay "+strconv.FormatInt(maxDelay, 10))
+		assert.NoError(t, radix.Validate())
+	}
+}
+
+func TestRadixHeapHighBits(t *testing.T) {
+	heap := lib.NewRadixHeap()
+	keys := []*lib.KeyInt{
+		lib.NewKeyInt(1<<63, 0), lib.NewKeyInt(1, 0), lib.NewKeyInt(0, 1<<63),
+		lib.NewKeyInt(0, 0), lib.NewKeyInt(1, 1),
+	}
+	heap.Construction(keys)
+	assert.NoError(t, heap.Validate())
+	assertEqualKeys(t, sortedKeys(keys), []*lib.KeyInt{
+		heap.SupprMin(), heap.SupprMin(), heap.SupprMin(), heap.SupprMin(), heap.SupprMin(),
+	}, "high bits")
+	assert.Nil(t, heap.SupprMin())
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkEventSimulation(b *testing.B) {
+	heaps := []struct {
+		name string
+		new  func() lib.MinHeap
+	}{
+		{"heapArray", func() lib.MinHeap { return lib.NewMinHeapArray() }},
+		{"heapBinomial", func() lib.MinHeap { return lib.NewMinHeapBinomial() }},
+		{"heapRadix", func() lib.MinHeap { return lib.NewRadixHeap() }},
+	}
+	for _, pending := range []int{1000, 100000} {
+		for _, heap := range heaps {
+			b.Run(heap.name+"/cles_"+strconv.Itoa(pending), func(b *testing.B) {
+				r := rand.New(rand.NewSource(1))
+				queue := &eventQueue{heap.new(), make(map[*lib.KeyInt]uint64)}
+				for i := 0; i < pending; i++ {
+					queue.schedule(uint64(r.Int63n(1 << 20)))
+				}
+				b.ResetTimer()
+				for n := 0; n < b.N; n++ {
+					key := queue.heap.SupprMin()
+					queue.schedule(queue.times[key] + uint64(r.Int63n(1<<20)))
+					delete(queue.times, key)
+				}
+			})
+		}
+	}
+}
@@ -1,17 +1,34 @@
 package lib
 
+import (
+	"fmt"
+	"io"
+)
+
 type SearchTreeNode struct {
 	data  *KeyInt
 	left  *SearchTreeNode
 	right *SearchTreeNode
+	// value of the key when the tree backs an OrderedMap
+	value any
+	// number of keys of the subtree, this one included
+	size int
 }
 
 func (node *SearchTreeNode) isNil() bool {
 	return (node == nil || node.data == nil)
 }
 
+func (node *SearchTreeNode) subtreeSize() int {
+	if node.isNil() {
+		return 0
+	}
+	return node.size
+}
+
 type SearchTree struct {
-	root *SearchTreeNode
+	root   *SearchTreeNode
+	tracer Tracer
 }
 
 func NewSearchTree() *SearchTree {
@@ -20,16 +37,31 @@ func NewSearchTree() *SearchTree {
 	}
 }
 
+// Register a function called on every compare and alloc, nil disables it
+func (tree *SearchTree) SetTracer(tracer Tracer) {
+	tree.tracer = tracer
+}
+
+func (tree *SearchTree) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt) {
+	if tree.tracer != nil {
+		tree.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: tree})
+	}
+}
+
 func (tree *SearchTree) insertNode(node *SearchTreeNode, key *KeyInt) {
+	node.size++
+	tree.trace(TraceCompare, key, node.data)
 	if key.Inf(node.data) {
 		if node.left.isNil() {
-			node.left = &SearchTreeNode{data: key}
+			node.left = &SearchTreeNode{data: key, size: 1}
+			tree.trace(TraceAlloc, key, nil)
 		} else {
 			tree.insertNode(node.left, key)
 		}
 	} else {
 		if node.right.isNil() {
-			node.right = &SearchTreeNode{data: key}
+			node.right = &SearchTreeNode{data: key, size: 1}
+			tree.trace(TraceAlloc, key, nil)
 		} else {
 			tree.insertNode(node.right, key)
 		}
@@ -39,16 +71,50 @@ func (tree *SearchTree) insertNode(node *SearchTreeNode, key *KeyInt) {
 func (tree *SearchTree) Insert(key *KeyInt) {
 	if tree.root.data == nil {
 		tree.root.data = key
+		tree.root.size = 1
 		return
 	}
 
 	tree.insertNode(tree.root, key)
 }
 
+// Return the node of the key, inserting it when missing; inserted tells
+// which one happened
+func (tree *SearchTree) insertUnique(key *KeyInt) (node *SearchTreeNode, inserted bool) {
+	if tree.root.data == nil {
+		tree.root.data = key
+		tree.root.size = 1
+		return tree.root, true
+	}
+
+	link := &tree.root
+	path := make([]*SearchTreeNode, 0)
+	for !(*link).isNil() {
+		node = *link
+		if key.Eq(node.data) {
+			return node, false
+		}
+		path = append(path, node)
+		tree.trace(TraceCompare, key, node.data)
+		if key.Inf(node.data) {
+			link = &node.left
+		} else {
+			link = &node.right
+		}
+	}
+	*link = &SearchTreeNode{data: key, size: 1}
+	tree.trace(TraceAlloc, key, nil)
+	for _, node := range path {
+		node.size++
+	}
+	return *link, true
+}
+
 func (tree *SearchTree) getNode(node *SearchTreeNode, key *KeyInt) *KeyInt {
 	if key.Eq(node.data) {
 		return node.data
 	}
+	tree.trace(TraceCompare, key, node.data)
 	if key.Inf(node.data) && !node.left.isNil() {
 		return tree.getNode(node.left, key)
 	}
@@ -65,6 +131,194 @@ func (tree *SearchTree) Get(key *KeyInt) *KeyInt {
 	return tree.getNode(tree.root, key)
 }
 
+// Return the link to the node of the key, the link to the nil child where
+// it would be when missing
+func (tree *SearchTree) findLink(key *KeyInt) **SearchTreeNode {
+	link := &tree.root
+	for !(*link).isNil() {
+		node := *link
+		if key.Eq(node.data) {
+			return link
+		}
+		tree.trace(TraceCompare, key, node.data)
+		if key.Inf(node.data) {
+			link = &node.left
+		} else {
+			link = &node.right
+		}
+	}
+	return link
+}
+
+// Return the node of the key, nil when missing
+func (tree *SearchTree) findNode(key *KeyInt) *SearchTreeNode {
+	if node := *tree.findLink(key); !node.isNil() {
+		return node
+	}
+	return nil
+}
+
+// Number of keys of the tree, equal keys included
+func (tree *SearchTree) Len() int {
+	return tree.root.subtreeSize()
+}
+
+/*
+Delete
+
+Remove a key equal to the given one and return whether there was one;
+
+ 1. A node with at most one child is replaced by that child.
+ 2. A node with two children takes the key of its successor, the smallest
+    key of its right subtree, and the successor is replaced by its right
+    child.
+*/
+func (tree *SearchTree) Delete(key *KeyInt) bool {
+	link := tree.findLink(key)
+	if (*link).isNil() {
+		return false
+	}
+	// the subtrees on the path lose a key, the path is the same as the one
+	// of findLink
+	for node := tree.root; node != *link; {
+		node.size--
+		if key.Inf(node.data) {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	tree.deleteLink(link)
+	if tree.root == nil {
+		tree.root = &SearchTreeNode{}
+	}
+	return true
+}
+
+func (tree *SearchTree) deleteLink(link **SearchTreeNode) {
+	node := *link
+	tree.trace(TraceCut, node.data, nil)
+	switch {
+	case node.left.isNil():
+		*link = node.right
+	case node.right.isNil():
+		*link = node.left
+	default:
+		node.size--
+		successor := &node.right
+		for !(*successor).left.isNil() {
+			(*successor).size--
+			successor = &(*successor).left
+		}
+		node.data, node.value = (*successor).data, (*successor).value
+		*successor = (*successor).right
+	}
+}
+
+/**
+ * Order statistics
+ */
+
+// Return the number of keys strictly inferior to the key, in O(height): the
+// tree is not balanced, that is O(log n) for keys inserted in random order
+// but O(n) for sorted ones, Treap.Rank stays in O(log n)
+func (tree *SearchTree) Rank(key *KeyInt) int {
+	rank := 0
+	for node := tree.root; !node.isNil(); {
+		tree.trace(TraceCompare, node.data, key)
+		if node.data.Inf(key) {
+			rank += node.left.subtreeSize() + 1
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return rank
+}
+
+// Return the key of rank k, the k-th smallest counting from 0, nil when k is
+// not in [0, Len()); in O(height), up to O(n) like Rank
+func (tree *SearchTree) Select(k int) *KeyInt {
+	if k < 0 || k >= tree.Len() {
+		return nil
+	}
+	node := tree.root
+	for {
+		left := node.left.subtreeSize()
+		switch {
+		case k < left:
+			node = node.left
+		case k == left:
+			return node.data
+		default:
+			k -= left + 1
+			node = node.right
+		}
+	}
+}
+
+// Return the number of keys within [lower, upper), nil bounds are unbounded
+func (tree *SearchTree) CountRange(lower *KeyInt, upper *KeyInt) int {
+	count := tree.Len()
+	if upper != nil {
+		count = tree.Rank(upper)
+	}
+	if lower != nil {
+		count -= tree.Rank(lower)
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
+}
+
+/**
+ * Iteration
+ */
+
+// Call yield on every key in increasing order, equal keys in their order of
+// insertion, until it returns false
+func (tree *SearchTree) Ascend(yield func(key *KeyInt) bool) {
+	tree.Range(nil, nil, yield)
+}
+
+// Call yield on every key within [lower, upper) in increasing order until it
+// returns false, nil bounds are unbounded
+func (tree *SearchTree) Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool) {
+	tree.rangeNodes(lower, upper, func(node *SearchTreeNode) bool {
+		return yield(node.data)
+	})
+}
+
+func (tree *SearchTree) rangeNodes(lower *KeyInt, upper *KeyInt, yield func(node *SearchTreeNode) bool) {
+	// nodes whose left subtree is done, the next one to yield on top
+	stack := make([]*SearchTreeNode, 0)
+	push := func(node *SearchTreeNode) {
+		for !node.isNil() {
+			if lower != nil && node.data.Inf(lower) {
+				// the whole left subtree is below the range
+				node = node.right
+				continue
+			}
+			stack = append(stack, node)
+			node = node.left
+		}
+	}
+
+	push(tree.root)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if upper != nil && !node.data.Inf(upper) {
+			return
+		}
+		if !yield(node) {
+			return
+		}
+		push(node.right)
+	}
+}
+
 func (tree *SearchTree) nodeMaxLevel(node *SearchTreeNode) int {
 	if node.isNil() {
 		return 0
@@ -75,3 +329,92 @@ func (tree *SearchTree) nodeMaxLevel(node *SearchTreeNode) int {
 func (tree *SearchTree) MaxLevel() int {
 	return tree.nodeMaxLevel(tree.root)
 }
+
+// Check that every key is within [lower, upper), nil bounds are unbounded
+func (tree *SearchTree) validateNode(node *SearchTreeNode, lower *KeyInt, upper *KeyInt) error {
+	if node == nil {
+		return nil
+	}
+	if node.data == nil {
+		if !node.left.isNil() || !node.right.isNil() {
+			return fmt.Errorf("empty node with children")
+		}
+		return nil
+	}
+	if lower != nil && node.data.Inf(lower) {
+		return fmt.Errorf("%v is in the right subtree of %v", node.data, lower)
+	}
+	if upper != nil && !node.data.Inf(upper) {
+		return fmt.Errorf("%v is in the left subtree of %v", node.data, upper)
+	}
+
+	if err := tree.validateNode(node.left, lower, node.data); err != nil {
+		return err
+	}
+	return tree.validateNode(node.right, node.data, upper)
+}
+
+// Check the binary search tree order, smaller keys on the left and
+// greater or equal ones on the right, and the size of every subtree
+func (tree *SearchTree) Validate() error {
+	if tree.root == nil {
+		return fmt.Errorf("missing root")
+	}
+	if err := tree.validateNode(tree.root, nil, nil); err != nil {
+		return err
+	}
+	_, err := tree.validateSize(tree.root)
+	return err
+}
+
+// Check that every node counts the keys of its subtree, return the count
+func (tree *SearchTree) validateSize(node *SearchTreeNode) (int, error) {
+	if node.isNil() {
+		return 0, nil
+	}
+	left, err := tree.validateSize(node.left)
+	if err != nil {
+		return 0, err
+	}
+	right, err := tree.validateSize(node.right)
+	if err != nil {
+		return 0, err
+	}
+	if size := left + right + 1; size != node.size {
+		return 0, fmt.Errorf("subtree of %v has %d keys, it counts %d", node.data, size, node.size)
+	}
+	return node.size, nil
+}
+
+/**
+ * Vizualisation
+ */
+
+func (tree *SearchTree) vizNode(node *SearchTreeNode) *vizNode {
+	if node.isNil() {
+		return nil
+	}
+	return newVizBinaryNode(node.data,
+		tree.vizNode(node.left), tree.vizNode(node.right))
+}
+
+func (tree *SearchTree) vizForest() []*vizNode {
+	if tree.root.isNil() {
+		return nil
+	}
+	return []*vizNode{tree.vizNode(tree.root)}
+}
+
+func (tree *SearchTree) Viz() []byte {
+	return tree.VizFormat(KeyString)
+}
+
+// Return the tree as a DOT graph, nodes are labelled with the given formatter
+func (tree *SearchTree) VizFormat(format KeyFormatter) []byte {
+	return vizDot(tree.vizForest(), vizOptions{format: format})
+}
+
+// Draw the tree in the terminal, a missing child is drawn when its sibling exists
+func (tree *SearchTree) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, tree.vizForest(), opts)
+}
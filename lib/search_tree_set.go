@@ -0,0 +1,143 @@
+package lib
+
+/*
+Set algebra
+
+The operations see the trees as sets, equal keys counting once. They walk
+both trees in order and merge the two sorted sequences in O(n + m)
+compares, then build the result as a balanced tree in O(n + m): its height
+is log n whatever the height of the operands. The result shares the keys of
+the operands but not their values.
+*/
+
+// Which keys a merge keeps: the ones only in the left tree, the ones in
+// both and the ones only in the right tree
+type setMerge struct {
+	left  bool
+	both  bool
+	right bool
+}
+
+// Return the distinct keys of the tree in increasing order
+func (tree *SearchTree) distinctKeys() []*KeyInt {
+	keys := make([]*KeyInt, 0, tree.Len())
+	tree.Ascend(func(key *KeyInt) bool {
+		if len(keys) == 0 || !key.Eq(keys[len(keys)-1]) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	return keys
+}
+
+// Merge the distinct keys of both trees, keeping the ones selected by keep
+func (tree *SearchTree) merge(other *SearchTree, keep setMerge) *SearchTree {
+	lhs, rhs := tree.distinctKeys(), other.distinctKeys()
+	merged := make([]*KeyInt, 0, len(lhs)+len(rhs))
+	i, j := 0, 0
+	for i < len(lhs) && j < len(rhs) {
+		tree.trace(TraceCompare, lhs[i], rhs[j])
+		switch {
+		case lhs[i].Eq(rhs[j]):
+			if keep.both {
+				merged = append(merged, lhs[i])
+			}
+			i++
+			j++
+		case lhs[i].Inf(rhs[j]):
+			if keep.left {
+				merged = append(merged, lhs[i])
+			}
+			i++
+		default:
+			if keep.right {
+				merged = append(merged, rhs[j])
+			}
+			j++
+		}
+	}
+	if keep.left {
+		merged = append(merged, lhs[i:]...)
+	}
+	if keep.right {
+		merged = append(merged, rhs[j:]...)
+	}
+	return newSearchTreeSorted(merged)
+}
+
+// Return a balanced tree of the keys, which must be sorted and distinct
+func newSearchTreeSorted(keys []*KeyInt) *SearchTree {
+	tree := NewSearchTree()
+	if len(keys) > 0 {
+		tree.root = buildBalanced(keys)
+	}
+	return tree
+}
+
+func buildBalanced(keys []*KeyInt) *SearchTreeNode {
+	if len(keys) == 0 {
+		return nil
+	}
+	middle := len(keys) / 2
+	return &SearchTreeNode{
+		data:  keys[middle],
+		left:  buildBalanced(keys[:middle]),
+		right: buildBalanced(keys[middle+1:]),
+		size:  len(keys),
+	}
+}
+
+// Return the keys of either tree
+func (tree *SearchTree) Union(other *SearchTree) *SearchTree {
+	return tree.merge(other, setMerge{left: true, both: true, right: true})
+}
+
+// Return the keys of both trees
+func (tree *SearchTree) Intersection(other *SearchTree) *SearchTree {
+	return tree.merge(other, setMerge{both: true})
+}
+
+// Return the keys of the tree missing from the other one
+func (tree *SearchTree) Difference(other *SearchTree) *SearchTree {
+	return tree.merge(other, setMerge{left: true})
+}
+
+// Return the keys of exactly one of the trees
+func (tree *SearchTree) SymmetricDifference(other *SearchTree) *SearchTree {
+	return tree.merge(other, setMerge{left: true, right: true})
+}
+
+// Return whether every key of the tree is in the other one
+func (tree *SearchTree) IsSubset(other *SearchTree) bool {
+	lhs, rhs := tree.distinctKeys(), other.distinctKeys()
+	if len(lhs) > len(rhs) {
+		return false
+	}
+	j := 0
+	for _, key := range lhs {
+		for j < len(rhs) && rhs[j].Inf(key) {
+			tree.trace(TraceCompare, rhs[j], key)
+			j++
+		}
+		if j == len(rhs) || !rhs[j].Eq(key) {
+			return false
+		}
+		j++
+	}
+	return true
+}
+
+// Return whether both trees hold the same keys
+func (tree *SearchTree) Equal(other *SearchTree) bool {
+	lhs, rhs := tree.distinctKeys(), other.distinctKeys()
+	if len(lhs) != len(rhs) {
+		return false
+	}
+	for i := range lhs {
+		tree.trace(TraceCompare, lhs[i], rhs[i])
+		if !lhs[i].Eq(rhs[i]) {
+			return false
+		}
+	}
+	return true
+}
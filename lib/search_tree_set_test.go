@@ -0,0 +1,158 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Return a tree per play of the hashes of its words, repeated words included
+func getShakespearePlayTrees() map[string]*lib.SearchTree {
+	plays := make(map[string]*lib.SearchTree)
+	getShakespeareWords(func(word string, filename string) {
+		if plays[filename] == nil {
+			plays[filename] = lib.NewSearchTree()
+		}
+		plays[filename].Insert(lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	})
+	return plays
+}
+
+// Return the distinct keys of the tree as a set
+func treeSet(tree *lib.SearchTree) map[lib.KeyInt]bool {
+	set := make(map[lib.KeyInt]bool)
+	tree.Ascend(func(key *lib.KeyInt) bool {
+		set[*key] = true
+		return true
+	})
+	return set
+}
+
+func assertTreeSet(t *testing.T, expected map[lib.KeyInt]bool, tree *lib.SearchTree, msg string) {
+	assert.NoError(t, tree.Validate(), msg)
+	assert.Equal(t, len(expected), tree.Len(), msg)
+	assert.Equal(t, expected, treeSet(tree), msg)
+	// the result is balanced, its height is the one of a complete tree
+	assert.LessOrEqual(t, 1<<tree.MaxLevel(), 2*tree.Len()+1, msg)
+}
+
+func TestSetAlgebraShakespeare(t *testing.T) {
+	plays := getShakespearePlayTrees()
+	hamlet, macbeth := plays["hamlet.txt"], plays["macbeth.txt"]
+	lhs, rhs := treeSet(hamlet), treeSet(macbeth)
+
+	union, intersection := make(map[lib.KeyInt]bool), make(map[lib.KeyInt]bool)
+	difference, symmetric := make(map[lib.KeyInt]bool), make(map[lib.KeyInt]bool)
+	for key := range lhs {
+		union[key] = true
+		if rhs[key] {
+			intersection[key] = true
+		} else {
+			difference[key] = true
+			symmetric[key] = true
+		}
+	}
+	for key := range rhs {
+		union[key] = true
+		if !lhs[key] {
+			symmetric[key] = true
+		}
+	}
+
+	assertTreeSet(t, union, hamlet.Union(macbeth), "union")
+	assertTreeSet(t, intersection, hamlet.Intersection(macbeth), "intersection")
+	assertTreeSet(t, difference, hamlet.Difference(macbeth), "difference")
+	assertTreeSet(t, symmetric, hamlet.SymmetricDifference(macbeth), "symmetric difference")
+	t.Logf("hamlet: %d words, macbeth: %d words, %d in common",
+		len(lhs), len(rhs), len(intersection))
+
+	// the operands are left as they were
+	assert.Equal(t, lhs, treeSet(hamlet))
+	assert.Equal(t, rhs, treeSet(macbeth))
+}
+
+func TestSetAlgebraVocabulary(t *testing.T) {
+	// the union of the vocabularies of every play is the whole vocabulary
+	plays := getShakespearePlayTrees()
+	vocabulary := lib.NewSearchTree()
+	for _, play := range plays {
+		assert.True(t, play.Intersection(vocabulary).IsSubset(play))
+		vocabulary = vocabulary.Union(play)
+	}
+	assert.Equal(t, 23086, vocabulary.Len())
+
+	unique := lib.NewSearchTree()
+	for _, word := range getShakespeareUniqueWords() {
+		unique.Insert(lib.NewKeyIntFromBytes(lib.MD5([]byte(word))))
+	}
+	assert.True(t, vocabulary.Equal(unique))
+	assert.True(t, unique.Equal(vocabulary))
+	assert.Equal(t, 0, vocabulary.SymmetricDifference(unique).Len())
+
+	for name, play := range plays {
+		assert.True(t, play.IsSubset(vocabulary), name)
+		assert.False(t, vocabulary.IsSubset(play), name)
+		assert.False(t, play.Equal(vocabulary), name)
+		assert.Equal(t, 0, play.Difference(vocabulary).Len(), name)
+	}
+}
+
+func TestSetAlgebraEdgeCases(t *testing.T) {
+	keys := genKeys()
+	empty := lib.NewSearchTree()
+	tree := lib.NewSearchTree()
+	for _, key := range append(keys, keys[2], keys[2]) {
+		tree.Insert(key)
+	}
+	set := treeSet(tree)
+
+	// equal keys count once
+	assertTreeSet(t, set, tree.Union(tree), "union with itself")
+	assertTreeSet(t, set, tree.Intersection(tree), "intersection with itself")
+	assertTreeSet(t, set, tree.Union(empty), "union with empty")
+	assertTreeSet(t, set, empty.Union(tree), "empty union")
+	assertTreeSet(t, map[lib.KeyInt]bool{}, tree.Difference(tree), "difference with itself")
+	assertTreeSet(t, map[lib.KeyInt]bool{}, tree.Intersection(empty), "intersection with empty")
+	assertTreeSet(t, map[lib.KeyInt]bool{}, empty.SymmetricDifference(empty), "empty")
+
+	assert.True(t, tree.Equal(tree.Union(empty)))
+	assert.True(t, empty.IsSubset(tree))
+	assert.True(t, empty.IsSubset(empty))
+	assert.True(t, empty.Equal(lib.NewSearchTree()))
+	assert.False(t, tree.IsSubset(empty))
+
+	// a balanced result grows like any other tree
+	union := tree.Union(empty)
+	union.Insert(keys[0])
+	assert.True(t, union.Delete(keys[2]))
+	assert.False(t, union.Delete(keys[2]))
+	assert.NoError(t, union.Validate())
+	assert.Equal(t, 5, union.Len())
+
+	smaller := lib.NewSearchTree()
+	smaller.Insert(keys[1])
+	smaller.Insert(keys[3])
+	assert.True(t, smaller.IsSubset(tree))
+	smaller.Insert(lib.NewKeyInt(0, 35))
+	assert.False(t, smaller.IsSubset(tree))
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkSetAlgebraWords(b *testing.B) {
+	plays := getShakespearePlayTrees()
+	hamlet, macbeth := plays["hamlet.txt"], plays["macbeth.txt"]
+	b.Run("union/hamlet_macbeth", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			hamlet.Union(macbeth)
+		}
+	})
+	b.Run("intersection/hamlet_macbeth", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			hamlet.Intersection(macbeth)
+		}
+	})
+}
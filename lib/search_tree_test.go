@@ -3,7 +3,10 @@ package lib_test
 import (
 	"arithmos/lib"
 	"bufio"
+	"math/bits"
+	"math/rand"
 	"os"
+	"sort"
 	"strconv"
 	"testing"
 
@@ -16,9 +19,11 @@ func TestGet(t *testing.T) {
 	tree := lib.NewSearchTree()
 
 	assert.Nil(t, tree.Get(keys[0]))
+	assert.NoError(t, tree.Validate())
 
 	tree.Insert(keys[0])
 	assert.Equal(t, keys[0], tree.Get(keys[0]))
+	assert.NoError(t, tree.Validate())
 
 	tree.Insert(keys[4])
 	tree.Insert(keys[3])
@@ -28,6 +33,7 @@ func TestGet(t *testing.T) {
 	assert.Equal(t, keys[2], tree.Get(keys[2]))
 	assert.Equal(t, keys[3], tree.Get(keys[3]))
 	assert.Equal(t, keys[4], tree.Get(keys[4]))
+	assert.NoError(t, tree.Validate())
 }
 
 /**
@@ -108,37 +114,234 @@ func TestShakespeareUniqueWords(t *testing.T) {
 
 	// get max level of the tree
 	assert.Equal(t, 32, wordSet.MaxLevel())
+	assert.NoError(t, wordSet.Validate())
 }
 
 func TestShakespeareUniqueCollisionWords(t *testing.T) {
-	wordSet := lib.NewSearchTree()
-	keyWordsMap := make(map[string]string)
+	keyWords := lib.NewOrderedMap[string]()
 	collisionWords := make([]string, 0)
 
 	getShakespeareWords(func(word string, _ string) {
 		hash := lib.MD5([]byte(word))
 		key := lib.NewKeyIntFromBytes(hash)
 
-		// not already here
-		if wordSet.Get(key) == nil {
-			wordSet.Insert(key)
-			keyWordsMap[key.String()] = word
-		} else {
-			// collision on the key, check if the words already exist
-			existingWord := keyWordsMap[key.String()]
-			// different words give the same key -> collision
-			if word != existingWord {
-				if !slices.Contains(collisionWords, word) {
-					collisionWords = append(collisionWords, word)
-				}
-				if !slices.Contains(collisionWords, existingWord) {
-					collisionWords = append(collisionWords, existingWord)
-				}
+		// different words give the same key -> collision
+		existingWord, loaded := keyWords.GetOrInsert(key, word)
+		if loaded && word != existingWord {
+			if !slices.Contains(collisionWords, word) {
+				collisionWords = append(collisionWords, word)
+			}
+			if !slices.Contains(collisionWords, existingWord) {
+				collisionWords = append(collisionWords, existingWord)
 			}
 		}
 	})
 
 	assert.Equal(t, 0, len(collisionWords))
+	assert.Equal(t, 23086, keyWords.Len())
+	assert.NoError(t, keyWords.Validate())
+}
+
+func TestDelete(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_5000.txt")
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+	assert.Equal(t, len(keys), tree.Len())
+
+	for i, key := range keys {
+		assert.True(t, tree.Delete(key))
+		assert.Nil(t, tree.Get(key))
+		if i%500 == 0 {
+			assert.NoError(t, tree.Validate())
+		}
+	}
+	assert.Equal(t, 0, tree.Len())
+	assert.False(t, tree.Delete(keys[0]))
+	assert.NoError(t, tree.Validate())
+
+	// the tree is usable again once empty
+	tree.Insert(keys[0])
+	assert.Equal(t, keys[0], tree.Get(keys[0]))
+}
+
+func TestDeleteDuplicates(t *testing.T) {
+	keys := genKeys()
+	tree := lib.NewSearchTree()
+	for _, key := range append(keys, keys...) {
+		tree.Insert(key)
+	}
+	assert.True(t, tree.Delete(keys[2]))
+	assert.NotNil(t, tree.Get(keys[2]))
+	assert.True(t, tree.Delete(keys[2]))
+	assert.Nil(t, tree.Get(keys[2]))
+	assert.Equal(t, 8, tree.Len())
+	assert.NoError(t, tree.Validate())
+}
+
+func TestRange(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	sorted := sortedKeys(keys)
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+
+	all := make([]*lib.KeyInt, 0)
+	tree.Ascend(func(key *lib.KeyInt) bool {
+		all = append(all, key)
+		return true
+	})
+	assert.Equal(t, sorted, all)
+
+	// [sorted[100], sorted[200]) and the bounds missing from the tree
+	inRange := make([]*lib.KeyInt, 0)
+	tree.Range(sorted[100], sorted[200], func(key *lib.KeyInt) bool {
+		inRange = append(inRange, key)
+		return true
+	})
+	assert.Equal(t, sorted[100:200], inRange)
+
+	tree.Delete(sorted[100])
+	tree.Delete(sorted[200])
+	inRange = inRange[:0]
+	tree.Range(sorted[100], sorted[200], func(key *lib.KeyInt) bool {
+		inRange = append(inRange, key)
+		return true
+	})
+	assert.Equal(t, sorted[101:200], inRange)
+
+	// stop early
+	count := 0
+	tree.Range(nil, sorted[500], func(key *lib.KeyInt) bool {
+		count++
+		return count < 10
+	})
+	assert.Equal(t, 10, count)
+}
+
+// Index of the first key of sorted not inferior to the key
+func lowerBound(sorted []*lib.KeyInt, key *lib.KeyInt) int {
+	return sort.Search(len(sorted), func(i int) bool {
+		return !sorted[i].Inf(key)
+	})
+}
+
+func TestRankSelect(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	sorted := sortedKeys(keys)
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+
+	for i, key := range sorted {
+		assert.Equal(t, lowerBound(sorted, key), tree.Rank(key))
+		assert.True(t, key.Eq(tree.Select(i)))
+	}
+	assert.Nil(t, tree.Select(-1))
+	assert.Nil(t, tree.Select(len(keys)))
+
+	// keys missing from the tree
+	for _, key := range getKeysFromFile(t, keysDirName+"jeu_2_nb_cles_1000.txt") {
+		assert.Equal(t, lowerBound(sorted, key), tree.Rank(key))
+	}
+	assert.Equal(t, 0, lib.NewSearchTree().Rank(keys[0]))
+
+	// the sizes follow the deletions
+	for _, key := range keys[:10000] {
+		tree.Delete(key)
+	}
+	assert.NoError(t, tree.Validate())
+	remaining := sortedKeys(keys[10000:])
+	for i, key := range remaining {
+		assert.Equal(t, i, tree.Rank(key))
+		assert.True(t, key.Eq(tree.Select(i)))
+	}
+}
+
+func TestRankDuplicates(t *testing.T) {
+	keys := lib.GenKeys(5000, lib.KeyDuplicates, 1)
+	sorted := sortedKeys(keys)
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+	for i, key := range sorted {
+		assert.Equal(t, lowerBound(sorted, key), tree.Rank(key))
+		assert.True(t, key.Eq(tree.Select(i)))
+	}
+}
+
+func TestTreapRankSelect(t *testing.T) {
+	// sorted keys make a path of the search tree, the treap stays balanced
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	sorted := sortedKeys(keys)
+	treap := lib.NewTreap()
+	for _, key := range sorted {
+		treap.Insert(key)
+	}
+	assert.NoError(t, treap.Validate())
+	assert.Less(t, treap.MaxLevel(), 4*bits.Len(uint(len(keys))))
+
+	for i, key := range sorted {
+		assert.Equal(t, lowerBound(sorted, key), treap.Rank(key))
+		assert.True(t, key.Eq(treap.Select(i)))
+	}
+	assert.Nil(t, treap.Select(-1))
+	assert.Nil(t, treap.Select(len(keys)))
+	for _, key := range getKeysFromFile(t, keysDirName+"jeu_2_nb_cles_1000.txt") {
+		assert.Equal(t, lowerBound(sorted, key), treap.Rank(key))
+	}
+	assert.Equal(t, len(keys), treap.CountRange(nil, nil))
+	assert.Equal(t, 100, treap.CountRange(sorted[100], sorted[200]))
+	assert.Equal(t, 0, treap.CountRange(sorted[200], sorted[100]))
+
+	// the sizes follow the deletions, equal keys included
+	for _, key := range keys[:10000] {
+		assert.True(t, treap.Delete(key))
+	}
+	assert.False(t, treap.Delete(keys[0]))
+	for _, key := range lib.GenKeys(1000, lib.KeyDuplicates, 1) {
+		treap.Insert(key)
+	}
+	assert.NoError(t, treap.Validate())
+	remaining := setKeys(treap)
+	for i, key := range remaining {
+		assert.Equal(t, lowerBound(remaining, key), treap.Rank(key))
+		assert.True(t, key.Eq(treap.Select(i)))
+	}
+}
+
+func TestCountRange(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_5000.txt")
+	sorted := sortedKeys(keys)
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	bounds := getKeysFromFile(t, keysDirName+"jeu_2_nb_cles_1000.txt")
+	for i := 0; i < 1000; i++ {
+		lower, upper := bounds[r.Intn(len(bounds))], sorted[r.Intn(len(sorted))]
+		expected := lowerBound(sorted, upper) - lowerBound(sorted, lower)
+		if expected < 0 {
+			expected = 0
+		}
+		assert.Equal(t, expected, tree.CountRange(lower, upper))
+
+		counted := 0
+		tree.Range(lower, upper, func(*lib.KeyInt) bool {
+			counted++
+			return true
+		})
+		assert.Equal(t, counted, tree.CountRange(lower, upper))
+	}
+	assert.Equal(t, len(keys), tree.CountRange(nil, nil))
+	assert.Equal(t, 100, tree.CountRange(nil, sorted[100]))
+	assert.Equal(t, len(keys)-100, tree.CountRange(sorted[100], nil))
 }
 
 /**
@@ -271,3 +474,44 @@ func BenchmarkUnionWords(b *testing.B) {
 		}
 	})
 }
+
+// The search tree of sorted keys is a path, the treap keeps the ranks in
+// O(log n)
+func BenchmarkRankSorted(b *testing.B) {
+	keys := sortedKeys(getKeysFromFile(b, benchKeysPath(1, 20000)))
+	tree, treap := lib.NewSearchTree(), lib.NewTreap()
+	for _, key := range keys {
+		tree.Insert(key)
+		treap.Insert(key)
+	}
+	b.Run("searchTree/sorted_cles_20000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			tree.Rank(keys[n%len(keys)])
+		}
+		b.ReportMetric(float64(tree.MaxLevel()), "maxlevel")
+	})
+	b.Run("treap/sorted_cles_20000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			treap.Rank(keys[n%len(keys)])
+		}
+		b.ReportMetric(float64(treap.MaxLevel()), "maxlevel")
+	})
+}
+
+func BenchmarkRank(b *testing.B) {
+	keys := getKeysFromFile(b, benchKeysPath(1, 120000))
+	tree := lib.NewSearchTree()
+	for _, key := range keys {
+		tree.Insert(key)
+	}
+	b.Run("rank/cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			tree.Rank(keys[n%len(keys)])
+		}
+	})
+	b.Run("select/cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			tree.Select(n % len(keys))
+		}
+	})
+}
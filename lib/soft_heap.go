@@ -0,0 +1,443 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+/**
+ * Soft heap
+ *
+ * The simplified soft heap of Kaplan, Tarjan and Zwick: binary trees whose
+ * nodes hold lists of keys sharing a common key, the ckey, never inferior to
+ * any of them. A key whose ckey became superior to it is corrupted, SupprMin
+ * returns it by its ckey so it can come out too late. In exchange every
+ * operation is O(1) amortized but SupprMin in O(log 1/ε), and at most εn keys
+ * are corrupted at any time, n being the number of keys added.
+ */
+
+// Item of the list of a node
+type softItem struct {
+	key  *KeyInt
+	next *softItem
+}
+
+type softNode struct {
+	// common key of the items, nil while the list is empty
+	ckey *KeyInt
+	rank int
+	// number of items the node tries to hold, refilled from its children
+	// below half of it
+	target      int
+	first, last *softItem
+	count       int
+	// number of items equal to ckey, the others are corrupted
+	exact       int
+	left, right *softNode
+	// next root and root of the smallest ckey among this one and the next ones
+	next, suffixMin *softNode
+}
+
+func (node *softNode) isLeaf() bool {
+	return node.left == nil && node.right == nil
+}
+
+type SoftHeap struct {
+	roots   *softNode
+	epsilon float64
+	// ranks up to which the nodes hold a single item, without corruption,
+	// log 1/ε + 5 as in the analysis of Kaplan and Zwick
+	exactRank int
+	size      int
+	corrupted int
+	tracer    Tracer
+}
+
+/*
+NewSoftHeap returns a soft heap corrupting at most epsilon times the number
+of keys added, epsilon being in ]0, 1[.
+*/
+func NewSoftHeap(epsilon float64) *SoftHeap {
+	if !(epsilon > 0 && epsilon < 1) {
+		panic(fmt.Sprintf("soft heap error rate %v not in ]0, 1[", epsilon))
+	}
+	return &SoftHeap{
+		epsilon:   epsilon,
+		exactRank: int(math.Ceil(math.Log2(1/epsilon))) + 5,
+	}
+}
+
+/*
+SetTracer registers a function called on every compare, link, cut and alloc, nil disables it.
+*/
+func (heap *SoftHeap) SetTracer(tracer Tracer) {
+	heap.tracer = tracer
+}
+
+/*
+Reports an event to the tracer, if any.
+*/
+func (heap *SoftHeap) trace(op TraceOp, lhs *KeyInt, rhs *KeyInt) {
+	if heap.tracer != nil {
+		heap.tracer(TraceEvent{Op: op, Lhs: lhs, Rhs: rhs, source: heap})
+	}
+}
+
+/*
+Checks if the ckey of lhs is inferior to the one of rhs.
+*/
+func (heap *SoftHeap) inf(lhs *softNode, rhs *softNode) bool {
+	heap.trace(TraceCompare, lhs.ckey, rhs.ckey)
+	return lhs.ckey.Inf(rhs.ckey)
+}
+
+// Error rate given to NewSoftHeap
+func (heap *SoftHeap) Epsilon() float64 {
+	return heap.epsilon
+}
+
+// Number of keys of the heap
+func (heap *SoftHeap) Len() int {
+	return heap.size
+}
+
+// Number of keys of the heap whose ckey is superior to them, at most
+// Epsilon times the number of keys added
+func (heap *SoftHeap) Corrupted() int {
+	return heap.corrupted
+}
+
+/*
+Moves the list of the left child, the one with the smallest ckey, up into
+the node until the node holds its target or is a leaf; the items of the node
+whose key was its ckey are corrupted when the ckey grows.
+*/
+func (heap *SoftHeap) sift(node *softNode) {
+	for node.count < node.target && !node.isLeaf() {
+		if node.left == nil || (node.right != nil && heap.inf(node.right, node.left)) {
+			node.left, node.right = node.right, node.left
+		}
+		child := node.left
+
+		if node.count == 0 {
+			node.first, node.exact = child.first, child.exact
+		} else {
+			if node.ckey.Inf(child.ckey) {
+				heap.corrupted += node.exact
+				node.exact = 0
+			}
+			node.last.next = child.first
+			node.exact += child.exact
+		}
+		node.last = child.last
+		node.count += child.count
+		node.ckey = child.ckey
+
+		child.first, child.last, child.count, child.exact = nil, nil, 0, 0
+		if child.isLeaf() {
+			node.left = nil
+		} else {
+			heap.sift(child)
+		}
+	}
+}
+
+/*
+Makes a node of rank + 1 with the two trees as children and fills it from
+them.
+*/
+func (heap *SoftHeap) link(lhs *softNode, rhs *softNode) *softNode {
+	node := &softNode{rank: lhs.rank + 1, left: lhs, right: rhs, target: 1}
+	if node.rank > heap.exactRank {
+		node.target = (3*lhs.target + 1) / 2
+	}
+	heap.trace(TraceLink, lhs.ckey, rhs.ckey)
+	heap.sift(node)
+	return node
+}
+
+/*
+Recomputes the suffix minimums of the roots, from the given one back to the
+first root.
+*/
+func (heap *SoftHeap) updateSuffixMin(upTo *softNode) {
+	heap.updateSuffixMinFrom(heap.roots, upTo)
+}
+
+func (heap *SoftHeap) updateSuffixMinFrom(root *softNode, upTo *softNode) {
+	if root != upTo && root.next != nil {
+		heap.updateSuffixMinFrom(root.next, upTo)
+	}
+	root.suffixMin = root
+	if root.next != nil && heap.inf(root.next.suffixMin, root) {
+		root.suffixMin = root.next.suffixMin
+	}
+}
+
+/*
+Ajout
+
+Add a new root of rank 0 holding the key, then link it with the first root
+while they have the same rank, like incrementing a binary counter.
+*/
+func (heap *SoftHeap) Ajout(key *KeyInt) {
+	item := &softItem{key: key}
+	node := &softNode{ckey: key, target: 1, first: item, last: item, count: 1, exact: 1}
+	heap.trace(TraceAlloc, key, nil)
+	heap.size++
+
+	for heap.roots != nil && heap.roots.rank == node.rank {
+		next := heap.roots.next
+		node = heap.link(node, heap.roots)
+		heap.roots = next
+	}
+	node.next = heap.roots
+	heap.roots = node
+	heap.updateSuffixMin(node)
+}
+
+func (heap *SoftHeap) AjoutIteratif(keys []*KeyInt) {
+	for _, key := range keys {
+		heap.Ajout(key)
+	}
+}
+
+func (heap *SoftHeap) Construction(keys []*KeyInt) {
+	heap.AjoutIteratif(keys)
+}
+
+/*
+Union moves every key of the other heap into this one, the other heap must
+not be used anymore; both must have the same error rate.
+*/
+func (heap *SoftHeap) Union(other *SoftHeap) {
+	if heap.epsilon != other.epsilon {
+		panic(fmt.Sprintf("union of soft heaps of error rates %v and %v", heap.epsilon, other.epsilon))
+	}
+
+	// gather the roots by rank then link two roots of the same rank, from
+	// the lowest rank, like adding two binary numbers
+	maxRank := 0
+	for _, roots := range []*softNode{heap.roots, other.roots} {
+		for root := roots; root != nil; root = root.next {
+			maxRank = max(maxRank, root.rank)
+		}
+	}
+	slots := make([][]*softNode, maxRank+2)
+	for _, roots := range []*softNode{heap.roots, other.roots} {
+		for root := roots; root != nil; {
+			next := root.next
+			slots[root.rank] = append(slots[root.rank], root)
+			root = next
+		}
+	}
+
+	var first, last *softNode
+	for rank, nodes := range slots {
+		if len(nodes) >= 2 {
+			slots[rank+1] = append(slots[rank+1], heap.link(nodes[0], nodes[1]))
+			nodes = nodes[2:]
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+		nodes[0].next = nil
+		if last == nil {
+			first = nodes[0]
+		} else {
+			last.next = nodes[0]
+		}
+		last = nodes[0]
+	}
+
+	heap.roots = first
+	heap.size += other.size
+	heap.corrupted += other.corrupted
+	if last != nil {
+		heap.updateSuffixMin(last)
+	}
+	other.roots, other.size, other.corrupted = nil, 0, 0
+}
+
+/*
+SupprMin
+
+Remove a key of the root with the smallest ckey;
+
+ 1. Take the first item of the list of that root, it is the minimum unless
+    it is corrupted.
+ 2. If the list is down to half its target, refill it from the children, or
+    remove the root when it is an empty leaf.
+ 3. Update the suffix minimums of the roots up to that root.
+*/
+func (heap *SoftHeap) SupprMin() *KeyInt {
+	if heap.roots == nil {
+		return nil
+	}
+
+	node := heap.roots.suffixMin
+	item := node.first
+	node.first = item.next
+	node.count--
+	heap.size--
+	if item.key.Eq(node.ckey) {
+		node.exact--
+	} else {
+		heap.corrupted--
+	}
+	heap.trace(TraceCut, item.key, nil)
+
+	if node.count <= node.target/2 {
+		if !node.isLeaf() {
+			heap.sift(node)
+		} else if node.count == 0 {
+			heap.removeRoot(node)
+			return item.key
+		}
+	}
+	heap.updateSuffixMin(node)
+	return item.key
+}
+
+func (heap *SoftHeap) removeRoot(node *softNode) {
+	if heap.roots == node {
+		heap.roots = node.next
+		if heap.roots != nil {
+			heap.updateSuffixMin(heap.roots)
+		}
+		return
+	}
+	before := heap.roots
+	for before.next != node {
+		before = before.next
+	}
+	before.next = node.next
+	heap.updateSuffixMin(before)
+}
+
+/*
+Validate checks that the ranks of the roots increase, that no key is superior
+to the ckey of its node nor ckey inferior to the one of its parent, and that
+the counts of keys, of corrupted keys and the suffix minimums are up to date.
+*/
+func (heap *SoftHeap) Validate() error {
+	size, corrupted := 0, 0
+	var check func(node *softNode, parent *softNode) error
+	check = func(node *softNode, parent *softNode) error {
+		if node == nil {
+			return nil
+		}
+		count := 0
+		for item := node.first; item != nil; item = item.next {
+			if item.key == nil {
+				return fmt.Errorf("nil key in node of rank %d", node.rank)
+			}
+			if node.ckey.Inf(item.key) {
+				return fmt.Errorf("key %v superior to the ckey %v of its node", item.key, node.ckey)
+			}
+			if !item.key.Eq(node.ckey) {
+				corrupted++
+			}
+			count++
+			if item == node.last && item.next != nil {
+				return fmt.Errorf("node of rank %d has items after its last one", node.rank)
+			}
+		}
+		if count == 0 {
+			return fmt.Errorf("empty node of rank %d", node.rank)
+		}
+		if count != node.count {
+			return fmt.Errorf("node of rank %d has %d keys, it counts %d", node.rank, count, node.count)
+		}
+		if parent != nil && node.ckey.Inf(parent.ckey) {
+			return fmt.Errorf("heap order broken: ckey %v inferior to its parent %v", node.ckey, parent.ckey)
+		}
+		size += count
+		if err := check(node.left, node); err != nil {
+			return err
+		}
+		return check(node.right, node)
+	}
+
+	for root := heap.roots; root != nil; root = root.next {
+		if root.next != nil && root.next.rank <= root.rank {
+			return fmt.Errorf("root of rank %d after a root of rank %d", root.next.rank, root.rank)
+		}
+		if err := check(root, nil); err != nil {
+			return err
+		}
+		expected := root
+		if root.next != nil && root.next.suffixMin.ckey.Inf(root.ckey) {
+			expected = root.next.suffixMin
+		}
+		if root.suffixMin == nil || !root.suffixMin.ckey.Eq(expected.ckey) {
+			return fmt.Errorf("suffix minimum of the root of rank %d is not %v", root.rank, expected.ckey)
+		}
+	}
+	if size != heap.size {
+		return fmt.Errorf("%d keys in the trees, the heap counts %d", size, heap.size)
+	}
+	if corrupted != heap.corrupted {
+		return fmt.Errorf("%d corrupted keys, the heap counts %d", corrupted, heap.corrupted)
+	}
+	return nil
+}
+
+func (heap *SoftHeap) String() string {
+	text := "["
+	var walk func(node *softNode)
+	walk = func(node *softNode) {
+		if node == nil {
+			return
+		}
+		for item := node.first; item != nil; item = item.next {
+			if text != "[" {
+				text += ", "
+			}
+			text += item.key.String()
+		}
+		walk(node.left)
+		walk(node.right)
+	}
+	for root := heap.roots; root != nil; root = root.next {
+		walk(root)
+	}
+	return text + "]"
+}
+
+// Nodes drawn by their ckey, with their rank and their number of keys
+func (heap *SoftHeap) vizNode(node *softNode) *vizNode {
+	if node == nil {
+		return nil
+	}
+	viz := newVizBinaryNode(node.ckey, heap.vizNode(node.left), heap.vizNode(node.right))
+	viz.note = fmt.Sprintf("r%d ×%d", node.rank, node.count)
+	return viz
+}
+
+func (heap *SoftHeap) vizForest() []*vizNode {
+	forest := make([]*vizNode, 0)
+	for root := heap.roots; root != nil; root = root.next {
+		forest = append(forest, heap.vizNode(root))
+	}
+	return forest
+}
+
+func (heap *SoftHeap) Viz() []byte {
+	return heap.VizFormat(KeyString)
+}
+
+/*
+VizFormat returns the heap as a DOT graph, labelling nodes with the given formatter.
+*/
+func (heap *SoftHeap) VizFormat(format KeyFormatter) []byte {
+	return vizDot(heap.vizForest(), vizOptions{format: format})
+}
+
+/*
+Pretty draws the trees in the terminal.
+*/
+func (heap *SoftHeap) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, heap.vizForest(), opts)
+}
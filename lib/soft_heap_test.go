@@ -0,0 +1,141 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ lib.MinHeap = lib.NewSoftHeap(0.1)
+
+func drainHeap(heap lib.MinHeap) []*lib.KeyInt {
+	drained := make([]*lib.KeyInt, 0)
+	for key := heap.SupprMin(); key != nil; key = heap.SupprMin() {
+		drained = append(drained, key)
+	}
+	return drained
+}
+
+func TestSoftHeapExact(t *testing.T) {
+	// nodes of a rank up to log 1/ε + 5 hold a single key, none is corrupted
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	heap := lib.NewSoftHeap(1e-4)
+	heap.Construction(keys)
+	assert.NoError(t, heap.Validate())
+	assert.Equal(t, 0, heap.Corrupted())
+	assertEqualKeys(t, sortedKeys(keys), drainHeap(heap), "exact")
+}
+
+func TestSoftHeapCorruption(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_120000.txt")
+	n := len(keys)
+
+	for _, epsilon := range []float64{0.5, 0.2, 0.1, 0.01, 0.001} {
+		heap := lib.NewSoftHeap(epsilon)
+		heap.Construction(keys)
+		assert.NoError(t, heap.Validate())
+
+		// keys coming out after a greater one, each corrupted for a while
+		maxCorrupted, late := heap.Corrupted(), 0
+		var last *lib.KeyInt
+		for i := 0; i < n; i++ {
+			key := heap.SupprMin()
+			if last != nil && key.Inf(last) {
+				late++
+			}
+			if last == nil || last.Inf(key) {
+				last = key
+			}
+			if heap.Corrupted() > maxCorrupted {
+				maxCorrupted = heap.Corrupted()
+			}
+			if i == n/2 {
+				assert.NoError(t, heap.Validate())
+			}
+		}
+		assert.Nil(t, heap.SupprMin())
+		assert.Equal(t, 0, heap.Corrupted())
+
+		// at most εn keys are corrupted at any time
+		bound := epsilon * float64(n)
+		assert.LessOrEqual(t, float64(maxCorrupted), bound, "ε=%v", epsilon)
+		t.Logf("ε=%v: at most %d corrupted keys (%.4f n, bound %.0f), %d keys late",
+			epsilon, maxCorrupted, float64(maxCorrupted)/float64(n), bound, late)
+	}
+}
+
+func TestSoftHeapRandomized(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		epsilon := []float64{0.5, 0.1, 0.01}[seed%3]
+		heap := lib.NewSoftHeap(epsilon)
+		added, removed := 0, make([]*lib.KeyInt, 0)
+		keys := make([]*lib.KeyInt, 0)
+
+		for step := 0; step < 400; step++ {
+			switch r.Intn(3) {
+			case 0, 1:
+				key := genHeapOpKey(r)
+				heap.Ajout(key)
+				keys = append(keys, key)
+				added++
+			case 2:
+				if key := heap.SupprMin(); key != nil {
+					removed = append(removed, key)
+				}
+			}
+			msg := fmt.Sprintf("seed %d step %d", seed, step)
+			if !assert.NoError(t, heap.Validate(), msg) {
+				return
+			}
+			assert.LessOrEqual(t, float64(heap.Corrupted()), epsilon*float64(added), msg)
+		}
+		removed = append(removed, drainHeap(heap)...)
+		assert.ElementsMatch(t, keys, removed)
+	}
+}
+
+func TestSoftHeapUnion(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_5000.txt")
+	for _, split := range []int{0, 1, 1234, 2500, 5000} {
+		lhs, rhs := lib.NewSoftHeap(0.1), lib.NewSoftHeap(0.1)
+		lhs.Construction(keys[:split])
+		rhs.Construction(keys[split:])
+		lhs.Union(rhs)
+		assert.NoError(t, lhs.Validate())
+		assert.Equal(t, len(keys), lhs.Len())
+		assert.Equal(t, 0, rhs.Len())
+		assert.LessOrEqual(t, float64(lhs.Corrupted()), 0.1*float64(len(keys)))
+		assertEqualKeys(t, sortedKeys(keys), sortedKeys(drainHeap(lhs)), "union")
+	}
+
+	assert.Panics(t, func() { lib.NewSoftHeap(0.1).Union(lib.NewSoftHeap(0.2)) })
+	assert.Panics(t, func() { lib.NewSoftHeap(0) })
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkSoftHeap(b *testing.B) {
+	keys := getKeysFromFile(b, benchKeysPath(1, 120000))
+	b.Run("heapArray/cles_120000", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			heap := lib.NewMinHeapArray()
+			heap.Construction(keys)
+			drainHeap(heap)
+		}
+	})
+	for _, epsilon := range []float64{0.001, 0.01, 0.1, 0.5} {
+		b.Run(fmt.Sprintf("heapSoft_%v/cles_120000", epsilon), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				heap := lib.NewSoftHeap(epsilon)
+				heap.Construction(keys)
+				drainHeap(heap)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package lib
+
+import (
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// Number of 8b digits of a key, sorted by RadixSort from the lowest one
+	radixDigits = 16
+	// Below this number of keys SortKeys uses a comparison sort, the
+	// counting passes of RadixSort cost more than they save
+	radixSortThreshold = 256
+)
+
+// Order of two keys for slices.SortFunc, by Inf
+func compareKeys(a *KeyInt, b *KeyInt) int {
+	if a.Inf(b) {
+		return -1
+	}
+	if b.Inf(a) {
+		return 1
+	}
+	return 0
+}
+
+/*
+HeapSort
+
+Sort the keys in place in increasing order, without extra memory;
+
+ 1. Build a MaxHeapArray over the slice itself.
+ 2. Swap the maximum with the last key of the heap, shrink the heap by one
+    and sift the new root down, until the heap is empty.
+
+It runs in O(n log n) in every case but is not stable.
+*/
+func HeapSort(keys []*KeyInt) {
+	heap := newMaxHeapArray(keys)
+	for i := len(keys)/2 - 1; i >= 0; i-- {
+		heap.siftDown(i)
+	}
+
+	for end := len(keys) - 1; end > 0; end-- {
+		keys[0], keys[end] = keys[end], keys[0]
+		heap.array = keys[:end]
+		heap.siftDown(0)
+	}
+}
+
+// Return the 8b digit d of the key, 0 being the lowest one of u2
+func radixDigit(key *KeyInt, d int) byte {
+	if d < radixDigits/2 {
+		return byte(key.u2 >> (8 * d))
+	}
+	return byte(key.u1 >> (8 * (d - radixDigits/2)))
+}
+
+/*
+RadixSort
+
+Sort the keys in increasing order with a least significant digit radix sort
+on 8b digits;
+
+ 1. Count the occurrences of every digit of every key, in a single pass.
+ 2. For each digit, from the lowest to the highest, distribute the keys into
+    a buffer by their digit, keeping the order of the previous pass.
+ 3. Skip the digits shared by every key, common in the high bits of
+    clustered keys.
+
+It runs in O(n) with 16 passes at most, uses a buffer of n pointers and is
+stable.
+*/
+func RadixSort(keys []*KeyInt) {
+	if len(keys) < 2 {
+		return
+	}
+
+	var counts [radixDigits][256]int
+	for _, key := range keys {
+		for d := 0; d < radixDigits; d++ {
+			counts[d][radixDigit(key, d)]++
+		}
+	}
+
+	src, dst := keys, make([]*KeyInt, len(keys))
+	for d := 0; d < radixDigits; d++ {
+		count := &counts[d]
+		if count[radixDigit(src[0], d)] == len(src) {
+			continue
+		}
+
+		// start of each digit in dst
+		var offsets [256]int
+		offset := 0
+		for digit, c := range count {
+			offsets[digit] = offset
+			offset += c
+		}
+		for _, key := range src {
+			digit := radixDigit(key, d)
+			dst[offsets[digit]] = key
+			offsets[digit]++
+		}
+		src, dst = dst, src
+	}
+
+	if &src[0] != &keys[0] {
+		copy(keys, src)
+	}
+}
+
+/*
+SortKeys sorts the keys in increasing order, keeping equal keys in their
+order: RadixSort for most slices and a comparison sort below
+radixSortThreshold keys.
+*/
+func SortKeys(keys []*KeyInt) {
+	if len(keys) < radixSortThreshold {
+		slices.SortStableFunc(keys, compareKeys)
+		return
+	}
+	RadixSort(keys)
+}
@@ -0,0 +1,122 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+func compareKeys(a, b *lib.KeyInt) int {
+	if a.Inf(b) {
+		return -1
+	}
+	if b.Inf(a) {
+		return 1
+	}
+	return 0
+}
+
+// Reference sort of the tests, equal keys keep their order
+func sortedKeys(keys []*lib.KeyInt) []*lib.KeyInt {
+	sorted := slices.Clone(keys)
+	slices.SortStableFunc(sorted, compareKeys)
+	return sorted
+}
+
+var keySorts = map[string]func(keys []*lib.KeyInt){
+	"heapSort":  lib.HeapSort,
+	"radixSort": lib.RadixSort,
+	"sortKeys":  lib.SortKeys,
+}
+
+func TestSortKeys(t *testing.T) {
+	dists := []lib.KeyDistribution{lib.KeyUniform, lib.KeySorted, lib.KeyReverse,
+		lib.KeyNearlySorted, lib.KeyDuplicates, lib.KeyClustered, lib.KeyZipfian}
+	for name, sort := range keySorts {
+		for _, dist := range dists {
+			for _, n := range []int{0, 1, 2, 100, 5000} {
+				keys := lib.GenKeys(n, dist, 1)
+				expected := sortedKeys(keys)
+				sort(keys)
+				assertEqualKeys(t, expected, keys, name+"/"+dist.String())
+			}
+		}
+
+		keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+		expected := sortedKeys(keys)
+		sort(keys)
+		assertEqualKeys(t, expected, keys, name+"/cles_alea")
+	}
+}
+
+func TestSortKeysStable(t *testing.T) {
+	// every key has its own pointer, the stable sorts keep their order
+	keys := lib.GenKeys(5000, lib.KeyDuplicates, 1)
+	expected := sortedKeys(keys)
+	for _, name := range []string{"radixSort", "sortKeys"} {
+		sorted := slices.Clone(keys)
+		keySorts[name](sorted)
+		assert.Equal(t, expected, sorted, name)
+	}
+
+	small := slices.Clone(keys[:100])
+	lib.SortKeys(small)
+	assert.Equal(t, sortedKeys(keys[:100]), small)
+}
+
+func TestRadixSortHighBits(t *testing.T) {
+	// keys differing only by u1, then only by u2
+	keys := []*lib.KeyInt{
+		lib.NewKeyInt(3, 7), lib.NewKeyInt(1, 7), lib.NewKeyInt(2, 7),
+		lib.NewKeyInt(1, 9), lib.NewKeyInt(1, 1<<63), lib.NewKeyInt(1<<63, 0),
+	}
+	lib.RadixSort(keys)
+	assertEqualKeys(t, []*lib.KeyInt{
+		lib.NewKeyInt(1, 7), lib.NewKeyInt(1, 9), lib.NewKeyInt(1, 1<<63),
+		lib.NewKeyInt(2, 7), lib.NewKeyInt(3, 7), lib.NewKeyInt(1<<63, 0),
+	}, keys, "high bits")
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkSortKeys(b *testing.B) {
+	sorts := map[string]func(keys []*lib.KeyInt){
+		"slicesSort": func(keys []*lib.KeyInt) { slices.SortFunc(keys, compareKeys) },
+	}
+	for name, sort := range keySorts {
+		sorts[name] = sort
+	}
+	names := []string{"slicesSort", "heapSort", "radixSort", "sortKeys"}
+
+	for _, nbKeys := range []int{1000, 20000, 120000} {
+		path := benchKeysPath(1, nbKeys)
+		keys := getKeysFromFile(b, path)
+		for _, name := range names {
+			sort := sorts[name]
+			b.Run(name+"/"+filepath.Base(path), func(b *testing.B) {
+				sorted := make([]*lib.KeyInt, len(keys))
+				for n := 0; n < b.N; n++ {
+					copy(sorted, keys)
+					sort(sorted)
+				}
+			})
+		}
+	}
+
+	keys := lib.GenKeys(1000000, lib.KeyUniform, 1)
+	for _, name := range names {
+		sort := sorts[name]
+		b.Run(name+"/extra_jeu_nb_cles_1000000", func(b *testing.B) {
+			sorted := make([]*lib.KeyInt, len(keys))
+			for n := 0; n < b.N; n++ {
+				copy(sorted, keys)
+				sort(sorted)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+)
+
+type splayNode struct {
+	data  *KeyInt
+	left  *splayNode
+	right *splayNode
+}
+
+/*
+SplayTree is a binary search tree that moves every key it inserts or looks
+up to its root, so recently used keys are found fast. A single operation
+can walk O(n) nodes, a sequence of m operations walks O(m log n) of them.
+Equal keys can be on both sides of each other.
+*/
+type SplayTree struct {
+	root *splayNode
+	size int
+}
+
+func NewSplayTree() *SplayTree {
+	return &SplayTree{}
+}
+
+/*
+splay
+
+Top-down splay of the subtree of the node along the path given by dir, which
+is negative to go left, positive to go right and zero to stop; return the
+new root, the last node of the path;
+
+ 1. Walk down the path two nodes at a time, rotating them when both go
+    the same way (zig-zig).
+ 2. Hang the nodes left behind on the right of a left tree when going right,
+    on the left of a right tree when going left.
+ 3. The last node becomes the root, the left and right trees its subtrees.
+*/
+func (tree *SplayTree) splay(node *splayNode, dir func(data *KeyInt) int) *splayNode {
+	// header.right is the left tree, header.left the right tree
+	header := &splayNode{}
+	left, right := header, header
+	for {
+		if d := dir(node.data); d < 0 {
+			if node.left == nil {
+				break
+			}
+			if dir(node.left.data) < 0 {
+				child := node.left
+				node.left = child.right
+				child.right = node
+				node = child
+				if node.left == nil {
+					break
+				}
+			}
+			right.left = node
+			right = node
+			node = node.left
+		} else if d > 0 {
+			if node.right == nil {
+				break
+			}
+			if dir(node.right.data) > 0 {
+				child := node.right
+				node.right = child.left
+				child.left = node
+				node = child
+				if node.right == nil {
+					break
+				}
+			}
+			left.right = node
+			left = node
+			node = node.right
+		} else {
+			break
+		}
+	}
+	left.right = node.left
+	right.left = node.right
+	node.left = header.right
+	node.right = header.left
+	return node
+}
+
+// Splay the path of the key, the root is then a key equal to it or one of
+// its neighbours
+func (tree *SplayTree) splayKey(key *KeyInt) {
+	tree.root = tree.splay(tree.root, func(data *KeyInt) int {
+		switch {
+		case key.Inf(data):
+			return -1
+		case data.Inf(key):
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// Splay the greatest key of the subtree of the node, the new root has no
+// right child
+func (tree *SplayTree) splayMax(node *splayNode) *splayNode {
+	return tree.splay(node, func(*KeyInt) int { return 1 })
+}
+
+/*
+Insert
+
+ 1. Splay the key, the root is then its neighbour.
+ 2. The key becomes the root, the previous root and the side of it that is
+    beyond the key become its children.
+*/
+func (tree *SplayTree) Insert(key *KeyInt) {
+	node := &splayNode{data: key}
+	if tree.root != nil {
+		tree.splayKey(key)
+		if key.Inf(tree.root.data) {
+			node.left, node.right = tree.root.left, tree.root
+			tree.root.left = nil
+		} else {
+			node.left, node.right = tree.root, tree.root.right
+			tree.root.right = nil
+		}
+	}
+	tree.root = node
+	tree.size++
+}
+
+// Return a key equal to the given one, nil when missing; the tree is splayed
+// even when the key is missing
+func (tree *SplayTree) Get(key *KeyInt) *KeyInt {
+	if tree.root == nil {
+		return nil
+	}
+	tree.splayKey(key)
+	if key.Eq(tree.root.data) {
+		return tree.root.data
+	}
+	return nil
+}
+
+/*
+Delete
+
+Remove a key equal to the given one and return whether there was one;
+
+ 1. Splay the key, it is at the root when present.
+ 2. Splay the greatest key of the left subtree of the root, it has no right
+    child and takes the right subtree of the root.
+*/
+func (tree *SplayTree) Delete(key *KeyInt) bool {
+	if tree.Get(key) == nil {
+		return false
+	}
+	root := tree.root
+	if root.left == nil {
+		tree.root = root.right
+	} else {
+		tree.root = tree.splayMax(root.left)
+		tree.root.right = root.right
+	}
+	tree.size--
+	return true
+}
+
+// Number of keys of the tree, equal keys included
+func (tree *SplayTree) Len() int {
+	return tree.size
+}
+
+// Call yield on every key in increasing order until it returns false, the
+// tree is not splayed
+func (tree *SplayTree) Ascend(yield func(key *KeyInt) bool) {
+	tree.Range(nil, nil, yield)
+}
+
+// Call yield on every key within [lower, upper) in increasing order until it
+// returns false, nil bounds are unbounded; the tree is not splayed
+func (tree *SplayTree) Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool) {
+	// nodes whose left subtree is done, the next one to yield on top
+	stack := make([]*splayNode, 0)
+	push := func(node *splayNode) {
+		for node != nil {
+			if lower != nil && node.data.Inf(lower) {
+				// the whole left subtree is below the range
+				node = node.right
+				continue
+			}
+			stack = append(stack, node)
+			node = node.left
+		}
+	}
+
+	push(tree.root)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if upper != nil && !node.data.Inf(upper) {
+			return
+		}
+		if !yield(node.data) {
+			return
+		}
+		push(node.right)
+	}
+}
+
+// Height of the tree, walked iteratively since a splay tree can be a path of
+// all its keys
+func (tree *SplayTree) MaxLevel() int {
+	type level struct {
+		node  *splayNode
+		level int
+	}
+	maxLevel := 0
+	stack := make([]level, 0)
+	if tree.root != nil {
+		stack = append(stack, level{tree.root, 1})
+	}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		maxLevel = max(maxLevel, top.level)
+		for _, child := range []*splayNode{top.node.left, top.node.right} {
+			if child != nil {
+				stack = append(stack, level{child, top.level + 1})
+			}
+		}
+	}
+	return maxLevel
+}
+
+// Check that the keys are in increasing order and that the tree holds as
+// many keys as it counts
+func (tree *SplayTree) Validate() error {
+	size := 0
+	var last *KeyInt
+	var err error
+	tree.Ascend(func(key *KeyInt) bool {
+		if last != nil && key.Inf(last) {
+			err = fmt.Errorf("%v comes after %v", key, last)
+			return false
+		}
+		last = key
+		size++
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if size != tree.size {
+		return fmt.Errorf("splay tree has %d keys, it counts %d", size, tree.size)
+	}
+	return nil
+}
+
+/**
+ * Vizualisation
+ */
+
+func (tree *SplayTree) vizNode(node *splayNode) *vizNode {
+	if node == nil {
+		return nil
+	}
+	return newVizBinaryNode(node.data,
+		tree.vizNode(node.left), tree.vizNode(node.right))
+}
+
+func (tree *SplayTree) vizForest() []*vizNode {
+	if tree.root == nil {
+		return nil
+	}
+	return []*vizNode{tree.vizNode(tree.root)}
+}
+
+func (tree *SplayTree) Viz() []byte {
+	return tree.VizFormat(KeyString)
+}
+
+// Return the tree as a DOT graph, nodes are labelled with the given formatter
+func (tree *SplayTree) VizFormat(format KeyFormatter) []byte {
+	return vizDot(tree.vizForest(), vizOptions{format: format})
+}
+
+// Draw the tree in the terminal, a missing child is drawn when its sibling exists
+func (tree *SplayTree) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, tree.vizForest(), opts)
+}
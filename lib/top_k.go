@@ -0,0 +1,178 @@
+package lib
+
+import (
+	"io"
+	"math/bits"
+
+	"golang.org/x/exp/slices"
+)
+
+/*
+BottomK
+
+Return the k smallest keys of the iterator in increasing order, reading it
+once and holding at most k keys;
+
+ 1. Keep the k smallest keys seen so far in a MaxHeapArray.
+ 2. A key inferior to the maximum of a full heap replaces it.
+ 3. Empty the heap, greatest key first.
+
+It runs in O(n log k), against O(n + k log n) for a full Construction
+followed by k SupprMin, with O(k) memory instead of O(n).
+*/
+func BottomK(keys KeyIterator, k int) ([]*KeyInt, error) {
+	heap := NewMaxHeapArray()
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if heap.Len() < k {
+			heap.Ajout(key)
+		} else if k > 0 && key.Inf(heap.Max()) {
+			heap.replaceMax(key)
+		}
+	}
+
+	bottom := make([]*KeyInt, heap.Len())
+	for i := len(bottom) - 1; i >= 0; i-- {
+		bottom[i] = heap.SupprMax()
+	}
+	return bottom, nil
+}
+
+/*
+TopK returns the k greatest keys of the iterator in decreasing order, it is
+BottomK with a MinHeapArray holding the k greatest keys seen so far.
+*/
+func TopK(keys KeyIterator, k int) ([]*KeyInt, error) {
+	heap := NewMinHeapArray()
+	for {
+		key, err := keys.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if heap.Len() < k {
+			heap.Ajout(key)
+		} else if k > 0 && heap.Min().Inf(key) {
+			heap.replaceMin(key)
+		}
+	}
+
+	top := make([]*KeyInt, heap.Len())
+	for i := len(top) - 1; i >= 0; i-- {
+		top[i] = heap.SupprMin()
+	}
+	return top, nil
+}
+
+/**
+ * Quickselect
+ */
+
+/*
+SelectBottomK
+
+Reorder the keys in place so that the k smallest come first and return them,
+in no particular order;
+
+ 1. Partition the keys around the median of three of them, in three parts:
+    inferior, equal and superior to the pivot.
+ 2. Keep partitioning the part holding the k-th key until it is the equal one.
+
+It runs in O(n) on average. After 2 log n partitions the remaining part is
+sorted instead, bounding the worst case to O(n log n).
+*/
+func SelectBottomK(keys []*KeyInt, k int) []*KeyInt {
+	return quickselect(keys, k, func(a, b *KeyInt) bool { return a.Inf(b) })
+}
+
+/*
+SelectTopK reorders the keys in place so that the k greatest come first and
+returns them, in no particular order.
+*/
+func SelectTopK(keys []*KeyInt, k int) []*KeyInt {
+	return quickselect(keys, k, func(a, b *KeyInt) bool { return b.Inf(a) })
+}
+
+func quickselect(keys []*KeyInt, k int, less func(a, b *KeyInt) bool) []*KeyInt {
+	if k <= 0 {
+		return keys[:0]
+	}
+	if k >= len(keys) {
+		return keys
+	}
+
+	lo, hi := 0, len(keys)
+	for depth := 2 * bits.Len(uint(len(keys))); hi-lo > 1; depth-- {
+		if depth == 0 {
+			sortKeysFunc(keys[lo:hi], less)
+			break
+		}
+
+		lt, gt := partition3(keys[lo:hi], less)
+		switch {
+		case k < lo+lt:
+			hi = lo + lt
+		case k > lo+gt:
+			lo += gt
+		default:
+			return keys[:k]
+		}
+	}
+	return keys[:k]
+}
+
+/*
+Partition the keys around the median of the first, middle and last keys, so
+that keys[:lt] are less than the pivot, keys[lt:gt] equal to it and keys[gt:]
+greater than it.
+*/
+func partition3(keys []*KeyInt, less func(a, b *KeyInt) bool) (int, int) {
+	a, b, c := keys[0], keys[len(keys)/2], keys[len(keys)-1]
+	if less(b, a) {
+		a, b = b, a
+	}
+	if less(c, b) {
+		b = c
+		if less(b, a) {
+			b = a
+		}
+	}
+	pivot := b
+
+	lt, i, gt := 0, 0, len(keys)
+	for i < gt {
+		switch {
+		case less(keys[i], pivot):
+			keys[lt], keys[i] = keys[i], keys[lt]
+			lt++
+			i++
+		case less(pivot, keys[i]):
+			gt--
+			keys[gt], keys[i] = keys[i], keys[gt]
+		default:
+			i++
+		}
+	}
+	return lt, gt
+}
+
+// Sort the keys, the fallback of quickselect when the partitions are unbalanced
+func sortKeysFunc(keys []*KeyInt, less func(a, b *KeyInt) bool) {
+	slices.SortFunc(keys, func(a, b *KeyInt) int {
+		if less(a, b) {
+			return -1
+		}
+		if less(b, a) {
+			return 1
+		}
+		return 0
+	})
+}
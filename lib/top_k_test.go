@@ -0,0 +1,167 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+func TestMaxHeapArray(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	sorted := sortedKeys(keys)
+
+	for _, build := range []string{"Ajout", "Construction"} {
+		heap := lib.NewMaxHeapArray()
+		if build == "Ajout" {
+			heap.AjoutIteratif(keys)
+		} else {
+			heap.Construction(keys)
+		}
+		assert.NoError(t, heap.Validate(), build)
+		assert.Equal(t, len(keys), heap.Len(), build)
+
+		for i := len(sorted) - 1; i >= 0; i-- {
+			assert.Equal(t, sorted[i], heap.Max(), build)
+			assert.Equal(t, sorted[i], heap.SupprMax(), build)
+		}
+		assert.Nil(t, heap.Max(), build)
+		assert.Nil(t, heap.SupprMax(), build)
+	}
+}
+
+// Return the k greatest keys of sorted, in decreasing order
+func reversedTop(sorted []*lib.KeyInt, k int) []*lib.KeyInt {
+	top := slices.Clone(sorted[len(sorted)-k:])
+	slices.Reverse(top)
+	return top
+}
+
+func assertEqualKeys(t *testing.T, expected []*lib.KeyInt, actual []*lib.KeyInt, msg string) {
+	t.Helper()
+	if !assert.Equal(t, len(expected), len(actual), msg) {
+		return
+	}
+	for i := range expected {
+		if !assert.True(t, expected[i].Eq(actual[i]), "%s: key %d", msg, i) {
+			return
+		}
+	}
+}
+
+func TestTopK(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_20000.txt")
+	sorted := sortedKeys(keys)
+
+	for _, k := range []int{0, 1, 10, 1000, len(keys), len(keys) + 10} {
+		expected := k
+		if expected > len(keys) {
+			expected = len(keys)
+		}
+		msg := "k=" + strconv.Itoa(k)
+
+		bottom, err := lib.BottomK(lib.IterateKeys(keys), k)
+		assert.NoError(t, err)
+		assertEqualKeys(t, sorted[:expected], bottom, msg)
+
+		top, err := lib.TopK(lib.IterateKeys(keys), k)
+		assert.NoError(t, err)
+		assertEqualKeys(t, reversedTop(sorted, expected), top, msg)
+	}
+}
+
+func TestTopKDuplicates(t *testing.T) {
+	keys := lib.GenKeys(5000, lib.KeyDuplicates, 1)
+	sorted := sortedKeys(keys)
+
+	bottom, err := lib.BottomK(lib.IterateKeys(keys), 777)
+	assert.NoError(t, err)
+	assertEqualKeys(t, sorted[:777], bottom, "bottom")
+
+	top, err := lib.TopK(lib.IterateKeys(keys), 777)
+	assert.NoError(t, err)
+	assertEqualKeys(t, reversedTop(sorted, 777), top, "top")
+}
+
+func TestTopKError(t *testing.T) {
+	keys := genKeys()
+	_, err := lib.BottomK(&failingKeyIterator{lib.IterateKeys(keys), 3}, 2)
+	assert.EqualError(t, err, "disk on fire")
+	_, err = lib.TopK(&failingKeyIterator{lib.IterateKeys(keys), 3}, 2)
+	assert.EqualError(t, err, "disk on fire")
+}
+
+func TestSelectK(t *testing.T) {
+	dists := []lib.KeyDistribution{lib.KeyUniform, lib.KeySorted, lib.KeyReverse, lib.KeyDuplicates, lib.KeyZipfian}
+	for _, dist := range dists {
+		keys := lib.GenKeys(10000, dist, 1)
+		sorted := sortedKeys(keys)
+
+		for _, k := range []int{0, 1, 10, 5000, 9999, 10000, 10001} {
+			expected := k
+			if expected > len(keys) {
+				expected = len(keys)
+			}
+			msg := dist.String() + "/k=" + strconv.Itoa(k)
+
+			bottom := lib.SelectBottomK(slices.Clone(keys), k)
+			assertEqualKeys(t, sorted[:expected], sortedKeys(bottom), msg)
+
+			top := lib.SelectTopK(slices.Clone(keys), k)
+			assertEqualKeys(t, sorted[len(sorted)-expected:], sortedKeys(top), msg)
+		}
+	}
+}
+
+func TestSelectKInPlace(t *testing.T) {
+	keys := getKeysFromFile(t, keysDirName+"jeu_1_nb_cles_1000.txt")
+	selected := slices.Clone(keys)
+	bottom := lib.SelectBottomK(selected, 100)
+
+	// the keys are only reordered and the selection is the head of the slice
+	assert.Same(t, &selected[0], &bottom[0])
+	assert.ElementsMatch(t, keys, selected)
+	last := bottom[0]
+	for _, key := range bottom {
+		if last.Inf(key) {
+			last = key
+		}
+	}
+	for _, key := range selected[100:] {
+		assert.False(t, key.Inf(last))
+	}
+}
+
+/**
+ * Benchmarks
+ */
+
+func BenchmarkBottomK(b *testing.B) {
+	keys := lib.GenKeys(1000000, lib.KeyUniform, 1)
+	for _, k := range []int{10, 1000, 100000} {
+		name := "k_" + strconv.Itoa(k) + "/cles_1000000"
+		b.Run("construction/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				heap := lib.NewMinHeapArray()
+				heap.Construction(keys)
+				for i := 0; i < k; i++ {
+					heap.SupprMin()
+				}
+			}
+		})
+		b.Run("bottomK/"+name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				lib.BottomK(lib.IterateKeys(keys), k)
+			}
+		})
+		b.Run("quickselect/"+name, func(b *testing.B) {
+			selected := make([]*lib.KeyInt, len(keys))
+			for n := 0; n < b.N; n++ {
+				copy(selected, keys)
+				lib.SelectBottomK(selected, k)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// TraceOp is the kind of structural event reported to a Tracer
+type TraceOp int
+
+const (
+	// Two keys are compared with KeyInt.Inf
+	TraceCompare TraceOp = iota
+	// Two keys exchange their position
+	TraceSwap
+	// A binomial tree is linked under the root of another one
+	TraceLink
+	// A key or a subtree is detached from its parent
+	TraceCut
+	// A node is allocated to hold the key, the array heap has no nodes
+	TraceAlloc
+)
+
+func (op TraceOp) String() string {
+	switch op {
+	case TraceCompare:
+		return "compare"
+	case TraceSwap:
+		return "swap"
+	case TraceLink:
+		return "link"
+	case TraceCut:
+		return "cut"
+	case TraceAlloc:
+		return "alloc"
+	}
+	return fmt.Sprintf("TraceOp(%d)", int(op))
+}
+
+// Any structure that can be drawn, used to snapshot a traced structure
+type vizSource interface {
+	vizForest() []*vizNode
+}
+
+// TraceEvent describes a single step of a heap operation, the keys involved
+// are in Lhs and Rhs (Rhs is nil for a cut and an alloc)
+//
+// For a link, Lhs is the new root and Rhs the root of the linked subtree
+type TraceEvent struct {
+	Op  TraceOp
+	Lhs *KeyInt
+	Rhs *KeyInt
+	// structure as it is right after the event
+	source vizSource
+}
+
+func (event TraceEvent) String() string {
+	if event.Rhs == nil {
+		return fmt.Sprintf("%v %v", event.Op, event.Lhs)
+	}
+	return fmt.Sprintf("%v %v %v", event.Op, event.Lhs, event.Rhs)
+}
+
+// Tracer is called synchronously for every structural event of a heap
+type Tracer func(event TraceEvent)
+
+/**
+ * Counting
+ */
+
+// OpCounts counts the events of a traced structure, without the cost of
+// recording them, to measure the work of an operation independently of the
+// machine it runs on
+type OpCounts struct {
+	Compares int
+	Swaps    int
+	Links    int
+	Cuts     int
+	Allocs   int
+}
+
+// Return the tracer to give to the SetTracer method of a structure
+func (counts *OpCounts) Tracer() Tracer {
+	return func(event TraceEvent) {
+		switch event.Op {
+		case TraceCompare:
+			counts.Compares++
+		case TraceSwap:
+			counts.Swaps++
+		case TraceLink:
+			counts.Links++
+		case TraceCut:
+			counts.Cuts++
+		case TraceAlloc:
+			counts.Allocs++
+		}
+	}
+}
+
+func (counts *OpCounts) Reset() {
+	*counts = OpCounts{}
+}
+
+/**
+ * Trace recording
+ */
+
+// Fill color of the keys involved in each kind of event
+var traceColors = map[TraceOp]string{
+	TraceCompare: "lightblue",
+	TraceSwap:    "orange",
+	TraceLink:    "palegreen",
+	TraceCut:     "salmon",
+	TraceAlloc:   "khaki",
+}
+
+type traceFrame struct {
+	event  TraceEvent
+	forest []*vizNode
+}
+
+// Trace records the events of a heap along with a snapshot of the heap after
+// each of them, meant for small heaps since every snapshot copies the heap
+type Trace struct {
+	frames []traceFrame
+}
+
+func NewTrace() *Trace {
+	return &Trace{frames: make([]traceFrame, 0)}
+}
+
+// Return the tracer to give to the SetTracer method of a heap
+func (trace *Trace) Tracer() Tracer {
+	return func(event TraceEvent) {
+		frame := traceFrame{event: event}
+		if event.source != nil {
+			frame.forest = event.source.vizForest()
+		}
+		trace.frames = append(trace.frames, frame)
+	}
+}
+
+// Return every recorded event, in order
+func (trace *Trace) Events() []TraceEvent {
+	events := make([]TraceEvent, 0, len(trace.frames))
+	for _, frame := range trace.frames {
+		events = append(events, frame.event)
+	}
+	return events
+}
+
+func (trace *Trace) Len() int {
+	return len(trace.frames)
+}
+
+func (trace *Trace) frameOptions(i int, format KeyFormatter) vizOptions {
+	event := trace.frames[i].event
+	color := traceColors[event.Op]
+	highlight := map[*KeyInt]string{}
+	if event.Lhs != nil {
+		highlight[event.Lhs] = color
+	}
+	if event.Rhs != nil {
+		highlight[event.Rhs] = color
+	}
+
+	title := fmt.Sprintf("%d: %v", i+1, event.Op)
+	if event.Lhs != nil {
+		title += " " + format(event.Lhs)
+	}
+	if event.Rhs != nil {
+		title += " " + format(event.Rhs)
+	}
+
+	return vizOptions{format: format, highlight: highlight, title: title}
+}
+
+// Return the i-th frame as a DOT graph, the keys of the event are highlighted
+func (trace *Trace) Frame(i int, format KeyFormatter) []byte {
+	return vizDot(trace.frames[i].forest, trace.frameOptions(i, format))
+}
+
+// Write every frame as a numbered DOT file in the given directory,
+// frame_0001.dot, frame_0002.dot, ...
+func (trace *Trace) WriteDotFrames(dir string, format KeyFormatter) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for i := range trace.frames {
+		path := filepath.Join(dir, fmt.Sprintf("frame_%04d.dot", i+1))
+		if err := os.WriteFile(path, trace.Frame(i, format), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const traceHtmlScript = `<script>
+const frames = document.querySelectorAll(".frame");
+const counter = document.getElementById("counter");
+let current = 0;
+let timer = null;
+function show(i) {
+  frames[current].style.display = "none";
+  current = Math.max(0, Math.min(frames.length - 1, i));
+  frames[current].style.display = "block";
+  counter.textContent = (current + 1) + " / " + frames.length;
+}
+function play() {
+  if (timer) { clearInterval(timer); timer = null; return; }
+  timer = setInterval(() => {
+    if (current == frames.length - 1) { play(); return; }
+    show(current + 1);
+  }, 700);
+}
+document.addEventListener("keydown", (e) => {
+  if (e.key == "ArrowLeft") show(current - 1);
+  if (e.key == "ArrowRight") show(current + 1);
+  if (e.key == " ") play();
+});
+show(0);
+</script>
+`
+
+// Write the trace as a single self-contained HTML page, every frame is an
+// inline SVG and a small script steps through them (arrows, space to play)
+func (trace *Trace) WriteHTML(w io.Writer, title string, format KeyFormatter) error {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n"+
+		"<title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+	buf.WriteString("<div>\n" +
+		"<button onclick=\"show(0)\">&#x23EE;</button>\n" +
+		"<button onclick=\"show(current - 1)\">&#x25C0;</button>\n" +
+		"<button onclick=\"play()\">&#x23EF;</button>\n" +
+		"<button onclick=\"show(current + 1)\">&#x25B6;</button>\n" +
+		"<span id=\"counter\"></span>\n</div>\n")
+
+	for i, frame := range trace.frames {
+		buf.WriteString("<div class=\"frame\" style=\"display: none\">\n")
+		buf.Write(vizSvg(frame.forest, trace.frameOptions(i, format)))
+		buf.WriteString("</div>\n")
+	}
+	if len(trace.frames) == 0 {
+		buf.WriteString("<div class=\"frame\">empty trace</div>\n")
+	}
+
+	buf.WriteString(traceHtmlScript)
+	buf.WriteString("</body>\n</html>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
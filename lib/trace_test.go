@@ -0,0 +1,129 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func traceString(trace *lib.Trace) []string {
+	events := make([]string, 0, trace.Len())
+	for _, event := range trace.Events() {
+		events = append(events, event.String())
+	}
+	return events
+}
+
+func TestTraceArray(t *testing.T) {
+	keys := genKeys()
+	heap := lib.NewMinHeapArray()
+	trace := lib.NewTrace()
+	heap.SetTracer(trace.Tracer())
+
+	heap.Ajout(keys[2])
+	heap.Ajout(keys[1])
+	heap.Ajout(keys[0])
+	assert.Equal(t, []string{
+		"compare 0-20 0-30",
+		"swap 0-20 0-30",
+		"compare 0-10 0-20",
+		"swap 0-10 0-20",
+	}, traceString(trace))
+
+	trace = lib.NewTrace()
+	heap.SetTracer(trace.Tracer())
+	assert.Equal(t, keys[0], heap.SupprMin())
+	assert.Equal(t, []string{
+		"swap 0-20 0-10",
+		"cut 0-10",
+		"compare 0-30 0-20",
+	}, traceString(trace))
+
+	heap.SetTracer(nil)
+	heap.Ajout(keys[4])
+	assert.Equal(t, 3, trace.Len())
+	assert.NoError(t, heap.Validate())
+}
+
+func TestTraceTree(t *testing.T) {
+	keys := genKeys()
+	heap := lib.NewMinHeapTree()
+	trace := lib.NewTrace()
+	heap.SetTracer(trace.Tracer())
+
+	heap.Ajout(keys[1])
+	heap.Ajout(keys[0])
+	assert.Equal(t, keys[0], heap.SupprMin())
+	assert.Equal(t, []string{
+		"alloc 0-10",
+		"compare 0-10 0-20",
+		"swap 0-10 0-20",
+		"swap 0-20 0-10",
+		"cut 0-10",
+	}, traceString(trace))
+	assert.NoError(t, heap.Validate())
+}
+
+func TestTraceBinomial(t *testing.T) {
+	keys := genKeys()
+	heap := lib.NewMinHeapBinomial()
+	trace := lib.NewTrace()
+	heap.SetTracer(trace.Tracer())
+
+	heap.Ajout(keys[1])
+	heap.Ajout(keys[0])
+	heap.Ajout(keys[2])
+	assert.Equal(t, keys[0], heap.SupprMin())
+	assert.Equal(t, []string{
+		"alloc 0-20",
+		"alloc 0-10",
+		"compare 0-10 0-20",
+		"link 0-10 0-20",
+		"alloc 0-30",
+		"compare 0-10 0-30",
+		"cut 0-10",
+		"compare 0-20 0-30",
+		"link 0-20 0-30",
+	}, traceString(trace))
+	assert.NoError(t, heap.Validate())
+
+	assert.Equal(t, "digraph structs {\nordering=out;\n"+
+		"labelloc=t;\nlabel=\"4: link 0-10 0-20\";\n"+
+		"n0 [label=\"0-10\\nB1\", style=filled, fillcolor=\"palegreen\"];\n"+
+		"n1 [label=\"0-20\", style=filled, fillcolor=\"palegreen\"];\n"+
+		"n0 -> n1;\n"+
+		"}", string(trace.Frame(3, lib.KeyString)))
+	vizBytes(trace.Frame(3, lib.KeyString), "trace_binomial_link")
+}
+
+func TestTraceExport(t *testing.T) {
+	heap := lib.NewMinHeapArray()
+	trace := lib.NewTrace()
+	heap.SetTracer(trace.Tracer())
+	heap.AjoutIteratif(genDescendingKeys(6))
+	heap.SupprMin()
+
+	dir := t.TempDir()
+	assert.NoError(t, trace.WriteDotFrames(dir, lib.KeyString))
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, trace.Len(), len(entries))
+	assert.Equal(t, "frame_0001.dot", entries[0].Name())
+
+	frame, err := os.ReadFile(filepath.Join(dir, "frame_0001.dot"))
+	assert.NoError(t, err)
+	assert.Equal(t, trace.Frame(0, lib.KeyString), frame)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, trace.WriteHTML(buf, "ajout", lib.KeyHex))
+	page := buf.String()
+	assert.Equal(t, trace.Len(), strings.Count(page, "<svg "))
+	assert.Contains(t, page, "<title>ajout</title>")
+	assert.Contains(t, page, "0x0000000000000000000000000000000")
+	assert.NotContains(t, page, "src=")
+}
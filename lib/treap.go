@@ -0,0 +1,357 @@
+package lib
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+type treapNode struct {
+	data     *KeyInt
+	priority uint32
+	left     *treapNode
+	right    *treapNode
+	// number of keys of the subtree, this one included
+	size int
+}
+
+func (node *treapNode) subtreeSize() int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
+func (node *treapNode) resize() {
+	node.size = node.left.subtreeSize() + node.right.subtreeSize() + 1
+}
+
+/*
+Treap is a binary search tree on its keys and a min-heap on random
+priorities drawn at insertion: its shape is the one of a search tree
+filled in random order, so its height is O(log n) in expectation whatever
+the order of the keys. Rotations can move equal keys on both sides of each
+other. Each node counts the keys of its subtree, for Rank and Select in
+O(log n) in expectation.
+*/
+type Treap struct {
+	root   *treapNode
+	size   int
+	random *rand.Rand
+}
+
+// Return an empty treap, the priorities come from a fixed seed so that the
+// shape of the treap only depends on its keys
+func NewTreap() *Treap {
+	return &Treap{random: rand.New(rand.NewSource(1))}
+}
+
+// The left child of the node at the link takes its place
+func (treap *Treap) rotateRight(link **treapNode) {
+	node := *link
+	child := node.left
+	node.left = child.right
+	child.right = node
+	*link = child
+	node.resize()
+	child.resize()
+}
+
+// The right child of the node at the link takes its place
+func (treap *Treap) rotateLeft(link **treapNode) {
+	node := *link
+	child := node.right
+	node.right = child.left
+	child.left = node
+	*link = child
+	node.resize()
+	child.resize()
+}
+
+/*
+Insert
+
+ 1. Insert the key as a leaf, as in a search tree, with a random priority.
+ 2. Rotate it up while its priority is inferior to the one of its parent.
+*/
+func (treap *Treap) Insert(key *KeyInt) {
+	treap.insertLink(&treap.root, &treapNode{data: key, priority: treap.random.Uint32(), size: 1})
+	treap.size++
+}
+
+func (treap *Treap) insertLink(link **treapNode, leaf *treapNode) {
+	node := *link
+	if node == nil {
+		*link = leaf
+		return
+	}
+	node.size++
+	if leaf.data.Inf(node.data) {
+		treap.insertLink(&node.left, leaf)
+		if node.left.priority < node.priority {
+			treap.rotateRight(link)
+		}
+	} else {
+		treap.insertLink(&node.right, leaf)
+		if node.right.priority < node.priority {
+			treap.rotateLeft(link)
+		}
+	}
+}
+
+// Return the link to a node of the key, the link to the nil child where it
+// would be when missing
+func (treap *Treap) findLink(key *KeyInt) **treapNode {
+	link := &treap.root
+	for *link != nil {
+		node := *link
+		if key.Eq(node.data) {
+			return link
+		}
+		if key.Inf(node.data) {
+			link = &node.left
+		} else {
+			link = &node.right
+		}
+	}
+	return link
+}
+
+func (treap *Treap) Get(key *KeyInt) *KeyInt {
+	if node := *treap.findLink(key); node != nil {
+		return node.data
+	}
+	return nil
+}
+
+/*
+Delete
+
+Remove a key equal to the given one and return whether there was one;
+
+ 1. Rotate the node down, its child of smaller priority taking its place,
+    until it has at most one child.
+ 2. Replace it by that child.
+*/
+func (treap *Treap) Delete(key *KeyInt) bool {
+	link := treap.findLink(key)
+	if *link == nil {
+		return false
+	}
+	// every node down to it loses a key, following the path of findLink
+	for node := treap.root; node != *link; {
+		node.size--
+		if key.Inf(node.data) {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	for {
+		node := *link
+		switch {
+		case node.left == nil:
+			*link = node.right
+			treap.size--
+			return true
+		case node.right == nil:
+			*link = node.left
+			treap.size--
+			return true
+		case node.left.priority < node.right.priority:
+			treap.rotateRight(link)
+			(*link).size--
+			link = &(*link).right
+		default:
+			treap.rotateLeft(link)
+			(*link).size--
+			link = &(*link).left
+		}
+	}
+}
+
+// Number of keys of the treap, equal keys included
+func (treap *Treap) Len() int {
+	return treap.size
+}
+
+/**
+ * Order statistics
+ */
+
+// Return the number of keys strictly inferior to the key, in O(log n) in
+// expectation
+func (treap *Treap) Rank(key *KeyInt) int {
+	rank := 0
+	for node := treap.root; node != nil; {
+		if node.data.Inf(key) {
+			rank += node.left.subtreeSize() + 1
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return rank
+}
+
+// Return the key of rank k, the k-th smallest counting from 0, nil when k is
+// not in [0, Len()); in O(log n) in expectation
+func (treap *Treap) Select(k int) *KeyInt {
+	if k < 0 || k >= treap.size {
+		return nil
+	}
+	node := treap.root
+	for {
+		left := node.left.subtreeSize()
+		switch {
+		case k < left:
+			node = node.left
+		case k == left:
+			return node.data
+		default:
+			k -= left + 1
+			node = node.right
+		}
+	}
+}
+
+// Return the number of keys within [lower, upper), nil bounds are unbounded
+func (treap *Treap) CountRange(lower *KeyInt, upper *KeyInt) int {
+	count := treap.size
+	if upper != nil {
+		count = treap.Rank(upper)
+	}
+	if lower != nil {
+		count -= treap.Rank(lower)
+	}
+	if count < 0 {
+		return 0
+	}
+	return count
+}
+
+// Call yield on every key in increasing order until it returns false
+func (treap *Treap) Ascend(yield func(key *KeyInt) bool) {
+	treap.Range(nil, nil, yield)
+}
+
+// Call yield on every key within [lower, upper) in increasing order until it
+// returns false, nil bounds are unbounded
+func (treap *Treap) Range(lower *KeyInt, upper *KeyInt, yield func(key *KeyInt) bool) {
+	// nodes whose left subtree is done, the next one to yield on top
+	stack := make([]*treapNode, 0)
+	push := func(node *treapNode) {
+		for node != nil {
+			if lower != nil && node.data.Inf(lower) {
+				// the whole left subtree is below the range
+				node = node.right
+				continue
+			}
+			stack = append(stack, node)
+			node = node.left
+		}
+	}
+
+	push(treap.root)
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if upper != nil && !node.data.Inf(upper) {
+			return
+		}
+		if !yield(node.data) {
+			return
+		}
+		push(node.right)
+	}
+}
+
+func (treap *Treap) nodeMaxLevel(node *treapNode) int {
+	if node == nil {
+		return 0
+	}
+	return max(treap.nodeMaxLevel(node.left), treap.nodeMaxLevel(node.right)) + 1
+}
+
+func (treap *Treap) MaxLevel() int {
+	return treap.nodeMaxLevel(treap.root)
+}
+
+// Check that every key is within [lower, upper], nil bounds are unbounded,
+// that no child has a smaller priority than its parent and the size of
+// every subtree; return the number of keys
+func (treap *Treap) validateNode(node *treapNode, lower *KeyInt, upper *KeyInt) (int, error) {
+	if node == nil {
+		return 0, nil
+	}
+	if lower != nil && node.data.Inf(lower) {
+		return 0, fmt.Errorf("%v is in the right subtree of %v", node.data, lower)
+	}
+	if upper != nil && upper.Inf(node.data) {
+		return 0, fmt.Errorf("%v is in the left subtree of %v", node.data, upper)
+	}
+	for _, child := range []*treapNode{node.left, node.right} {
+		if child != nil && child.priority < node.priority {
+			return 0, fmt.Errorf("%v has a smaller priority than its parent %v", child.data, node.data)
+		}
+	}
+
+	left, err := treap.validateNode(node.left, lower, node.data)
+	if err != nil {
+		return 0, err
+	}
+	right, err := treap.validateNode(node.right, node.data, upper)
+	if err != nil {
+		return 0, err
+	}
+	if node.size != left+right+1 {
+		return 0, fmt.Errorf("subtree of %v has %d keys, it counts %d", node.data, left+right+1, node.size)
+	}
+	return left + right + 1, nil
+}
+
+// Check the order of the keys, the heap order of the priorities and the
+// number of keys
+func (treap *Treap) Validate() error {
+	size, err := treap.validateNode(treap.root, nil, nil)
+	if err != nil {
+		return err
+	}
+	if size != treap.size {
+		return fmt.Errorf("treap has %d keys, it counts %d", size, treap.size)
+	}
+	return nil
+}
+
+/**
+ * Vizualisation
+ */
+
+func (treap *Treap) vizNode(node *treapNode) *vizNode {
+	if node == nil {
+		return nil
+	}
+	return newVizBinaryNode(node.data,
+		treap.vizNode(node.left), treap.vizNode(node.right))
+}
+
+func (treap *Treap) vizForest() []*vizNode {
+	if treap.root == nil {
+		return nil
+	}
+	return []*vizNode{treap.vizNode(treap.root)}
+}
+
+func (treap *Treap) Viz() []byte {
+	return treap.VizFormat(KeyString)
+}
+
+// Return the treap as a DOT graph, nodes are labelled with the given formatter
+func (treap *Treap) VizFormat(format KeyFormatter) []byte {
+	return vizDot(treap.vizForest(), vizOptions{format: format})
+}
+
+// Draw the treap in the terminal, a missing child is drawn when its sibling exists
+func (treap *Treap) Pretty(w io.Writer, opts PrettyOptions) error {
+	return prettyForest(w, treap.vizForest(), opts)
+}
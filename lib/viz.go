@@ -0,0 +1,261 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyFormatter returns the label displayed for a key in the visualizations
+type KeyFormatter func(key *KeyInt) string
+
+// Format a key like KeyInt.String, e.g. 0-10
+func KeyString(key *KeyInt) string {
+	return key.String()
+}
+
+// Format a key like the cles_alea files, e.g. 0x00000000000000000000000000000010
+func KeyHex(key *KeyInt) string {
+	return key.Hex()
+}
+
+// Logical view of a node shared by every visualization, independent of
+// the memory layout of the structure
+type vizNode struct {
+	key *KeyInt
+	// extra information shown next to the key, like the order of a binomial tree
+	note string
+	// binary nodes always have two children, nil for a missing left or right one
+	binary   bool
+	children []*vizNode
+}
+
+func newVizBinaryNode(key *KeyInt, left *vizNode, right *vizNode) *vizNode {
+	return &vizNode{key: key, binary: true, children: []*vizNode{left, right}}
+}
+
+// Return whether the node has at least one child
+func (node *vizNode) hasChildren() bool {
+	for _, child := range node.children {
+		if child != nil {
+			return true
+		}
+	}
+	return false
+}
+
+type vizOptions struct {
+	format KeyFormatter
+	// fill color of some keys, compared by address
+	highlight map[*KeyInt]string
+	// caption of the whole graph
+	title string
+}
+
+func (opts *vizOptions) label(node *vizNode) string {
+	label := opts.format(node.key)
+	if node.note != "" {
+		label += "\n" + node.note
+	}
+	return label
+}
+
+// Render a forest in the graphviz DOT format, roots are kept on the same rank
+// and linked in order when there is more than one
+func vizDot(forest []*vizNode, opts vizOptions) []byte {
+	buf := &bytes.Buffer{}
+	ids := 0
+	nextId := func() string {
+		id := fmt.Sprintf("n%d", ids)
+		ids++
+		return id
+	}
+
+	var writeNode func(node *vizNode) string
+	writeNode = func(node *vizNode) string {
+		id := nextId()
+		if color, ok := opts.highlight[node.key]; ok {
+			fmt.Fprintf(buf, "%s [label=%q, style=filled, fillcolor=%q];\n",
+				id, opts.label(node), color)
+		} else {
+			fmt.Fprintf(buf, "%s [label=%q];\n", id, opts.label(node))
+		}
+
+		if !node.binary {
+			for _, child := range node.children {
+				fmt.Fprintf(buf, "%s -> %s;\n", id, writeNode(child))
+			}
+			return id
+		}
+		if !node.hasChildren() {
+			return id
+		}
+
+		// invisible placeholders keep a lone right child on the right
+		for i, child := range node.children {
+			port := "sw"
+			if i == 1 {
+				port = "se"
+			}
+			if child == nil {
+				placeholder := nextId()
+				fmt.Fprintf(buf, "%s [shape=point, style=invis];\n", placeholder)
+				fmt.Fprintf(buf, "%s -> %s [style=invis];\n", id, placeholder)
+			} else {
+				fmt.Fprintf(buf, "%s -> %s [tailport=%s];\n", id, writeNode(child), port)
+			}
+		}
+		return id
+	}
+
+	buf.WriteString("digraph structs {\nordering=out;\n")
+	if opts.title != "" {
+		fmt.Fprintf(buf, "labelloc=t;\nlabel=%q;\n", opts.title)
+	}
+	roots := make([]string, 0, len(forest))
+	for _, root := range forest {
+		roots = append(roots, writeNode(root))
+	}
+	if len(roots) > 1 {
+		fmt.Fprintf(buf, "{ rank=same; ")
+		for _, root := range roots {
+			fmt.Fprintf(buf, "%s; ", root)
+		}
+		buf.WriteString("}\n")
+		for i := 1; i < len(roots); i++ {
+			fmt.Fprintf(buf, "%s -> %s [style=dashed, arrowhead=none];\n",
+				roots[i-1], roots[i])
+		}
+	}
+	buf.WriteString("}")
+
+	return buf.Bytes()
+}
+
+// Position of a node in a drawing, x is counted in leaf slots
+type vizPlacement struct {
+	node   *vizNode
+	x      float64
+	depth  int
+	parent int
+}
+
+// Lay out a forest as a tidy tree, leaves take one slot each from left to right
+// and parents are centered above their children
+func vizLayout(forest []*vizNode) ([]vizPlacement, int) {
+	placements := make([]vizPlacement, 0)
+	slots := 0
+
+	var place func(node *vizNode, depth int, parent int) float64
+	place = func(node *vizNode, depth int, parent int) float64 {
+		index := len(placements)
+		placements = append(placements, vizPlacement{node: node, depth: depth, parent: parent})
+
+		if !node.hasChildren() {
+			placements[index].x = float64(slots)
+			slots++
+			return placements[index].x
+		}
+
+		first, last := -1.0, -1.0
+		for _, child := range node.children {
+			var x float64
+			if child == nil {
+				// the missing side of a binary node still takes a slot
+				x = float64(slots)
+				slots++
+			} else {
+				x = place(child, depth+1, index)
+			}
+			if first < 0 {
+				first = x
+			}
+			last = x
+		}
+		placements[index].x = (first + last) / 2
+		return placements[index].x
+	}
+
+	for i, root := range forest {
+		if i > 0 {
+			slots++
+		}
+		place(root, 0, -1)
+	}
+
+	return placements, slots
+}
+
+// Render a forest as a standalone SVG image, no external layout tool needed
+func vizSvg(forest []*vizNode, opts vizOptions) []byte {
+	placements, slots := vizLayout(forest)
+
+	maxLen, maxDepth, maxLines := 1, 0, 1
+	for _, placement := range placements {
+		lines := strings.Split(opts.label(placement.node), "\n")
+		maxLines = max(maxLines, len(lines))
+		for _, line := range lines {
+			maxLen = max(maxLen, utf8.RuneCountInString(line))
+		}
+		maxDepth = max(maxDepth, placement.depth)
+	}
+
+	const pad, charWidth, lineHeight, levelHeight = 20, 8, 16, 40
+	slotWidth := maxLen*charWidth + 16
+	boxHeight := maxLines*lineHeight + 8
+	top := pad
+	if opts.title != "" {
+		top += lineHeight * 2
+	}
+	width := max(max(slots, 1)*slotWidth, utf8.RuneCountInString(opts.title)*charWidth) + 2*pad
+	height := top + (maxDepth+1)*(boxHeight+levelHeight) - levelHeight + pad
+
+	center := func(placement vizPlacement) (int, int) {
+		x := pad + int(placement.x*float64(slotWidth)) + slotWidth/2
+		y := top + placement.depth*(boxHeight+levelHeight) + boxHeight/2
+		return x, y
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<svg xmlns=\"http://www.w3.org/2000/svg\" "+
+		"width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" "+
+		"font-family=\"monospace\" font-size=\"13\">\n",
+		width, height, width, height)
+	if opts.title != "" {
+		fmt.Fprintf(buf, "<text x=\"%d\" y=\"%d\">%s</text>\n",
+			pad, pad+lineHeight, html.EscapeString(opts.title))
+	}
+
+	for _, placement := range placements {
+		if placement.parent < 0 {
+			continue
+		}
+		x1, y1 := center(placements[placement.parent])
+		x2, y2 := center(placement)
+		fmt.Fprintf(buf, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" "+
+			"stroke=\"black\"/>\n", x1, y1+boxHeight/2, x2, y2-boxHeight/2)
+	}
+
+	for _, placement := range placements {
+		x, y := center(placement)
+		fill := "white"
+		if color, ok := opts.highlight[placement.node.key]; ok {
+			fill = color
+		}
+		fmt.Fprintf(buf, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" "+
+			"rx=\"6\" fill=\"%s\" stroke=\"black\"/>\n",
+			x-slotWidth/2+4, y-boxHeight/2, slotWidth-8, boxHeight, fill)
+
+		lines := strings.Split(opts.label(placement.node), "\n")
+		firstLine := y - (len(lines)-1)*lineHeight/2 + 4
+		for i, line := range lines {
+			fmt.Fprintf(buf, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n",
+				x, firstLine+i*lineHeight, html.EscapeString(line))
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,74 @@
+package lib_test
+
+import (
+	"arithmos/lib"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVizHeaps(t *testing.T) {
+	expected := "digraph structs {\nordering=out;\n" +
+		"n0 [label=\"0-10\"];\n" +
+		"n1 [label=\"0-20\"];\n" +
+		"n2 [label=\"0-40\"];\n" +
+		"n1 -> n2 [tailport=sw];\n" +
+		"n3 [label=\"0-50\"];\n" +
+		"n1 -> n3 [tailport=se];\n" +
+		"n0 -> n1 [tailport=sw];\n" +
+		"n4 [label=\"0-30\"];\n" +
+		"n0 -> n4 [tailport=se];\n" +
+		"}"
+
+	runTestHeaps(func(heap lib.MinHeap) {
+		heap.Construction(genKeys())
+		assert.Equal(t, expected, string(heap.Viz()))
+	}, false)
+
+	heap := lib.NewMinHeapArray()
+	assert.Equal(t, "digraph structs {\nordering=out;\n}", string(heap.Viz()))
+	heap.Construction(genKeys())
+	vizBytes(heap.Viz(), "heap_array")
+	assert.Contains(t,
+		string(heap.VizFormat(lib.KeyHex)),
+		"n0 [label=\"0x0000000000000000000000000000000a\"];\n")
+}
+
+func TestVizBinomial(t *testing.T) {
+	heap := lib.NewMinHeapBinomial()
+	heap.Construction(genKeys())
+	assert.Equal(t, "digraph structs {\nordering=out;\n"+
+		"n0 [label=\"0-50\\nB0\"];\n"+
+		"n1 [label=\"0-10\\nB2\"];\n"+
+		"n2 [label=\"0-20\"];\n"+
+		"n1 -> n2;\n"+
+		"n3 [label=\"0-30\"];\n"+
+		"n4 [label=\"0-40\"];\n"+
+		"n3 -> n4;\n"+
+		"n1 -> n3;\n"+
+		"{ rank=same; n0; n1; }\n"+
+		"n0 -> n1 [style=dashed, arrowhead=none];\n"+
+		"}", string(heap.Viz()))
+	vizBytes(heap.Viz(), "binomial_heap")
+}
+
+func TestVizSearchTree(t *testing.T) {
+	keys := genKeys()
+	tree := lib.NewSearchTree()
+	tree.Insert(keys[1])
+	tree.Insert(keys[0])
+	tree.Insert(keys[3])
+	tree.Insert(keys[2])
+	assert.Equal(t, "digraph structs {\nordering=out;\n"+
+		"n0 [label=\"0-20\"];\n"+
+		"n1 [label=\"0-10\"];\n"+
+		"n0 -> n1 [tailport=sw];\n"+
+		"n2 [label=\"0-40\"];\n"+
+		"n3 [label=\"0-30\"];\n"+
+		"n2 -> n3 [tailport=sw];\n"+
+		"n4 [shape=point, style=invis];\n"+
+		"n2 -> n4 [style=invis];\n"+
+		"n0 -> n2 [tailport=se];\n"+
+		"}", string(tree.Viz()))
+	vizBytes(tree.Viz(), "search_tree")
+}
@@ -1,7 +1,71 @@
 package main
 
-import "fmt"
+import (
+	"arithmos/lib"
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"bench":      {"write tables and charts of the benchmark results", runBench},
+	"complexity": {"fit the benchmark results against complexity models", runComplexity},
+	"gen":        {"generate key files in the format of cles_alea", runGen},
+	"convert":    {"convert text key files to binary ones", runConvert},
+	"sort":       {"sort a key file larger than the memory", runSort},
+	"pretty":     {"draw a structure built from a key file", runPretty},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: arithmos <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
+
+// Read every key of a key file, either a cles_alea like text file or a
+// binary one
+func readKeyFile(path string) ([]*lib.KeyInt, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys, err := lib.ReadKeys(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return keys, nil
+}
 
 func main() {
-	fmt.Println("Hello World")
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "arithmos: unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "arithmos:", err)
+		os.Exit(1)
+	}
 }